@@ -9,6 +9,10 @@ terraform {
 provider "hidora" {
     host = "app.hidora.com"
     access_token = ""
+    default_region = "new"
+    default_node_labels = {
+        team = "devops"
+    }
 }
 
 data "hidora_create_env" "test-data" {
@@ -31,11 +35,158 @@ resource "hidora_create_env" "test-res" {
         env = {
             TEST = "test"
         }
-        extip = false
+        extip = false // Can be update, attach or detach public IPs
+        extip_count = 0 // Can be update
         fixedcloudlets = 4
         flexiblecloudlets = 8
         image = "python"
         mission = "cp"
         tag = "latest"
     }
-}
\ No newline at end of file
+}
+
+resource "hidora_letsencrypt" "test-ssl" {
+    env_name = "${hidora_create_env.test-res.id}"
+    nodegroup = "cp"
+    custom_domains = ["www.example.com", "example.com"] // Can be update
+}
+
+resource "hidora_jps_install" "test-jps" {
+    env_name = "${hidora_create_env.test-res.id}"
+    nodegroup = "cp"
+    manifest_url = "https://raw.githubusercontent.com/jelastic-jps/git-push-deploy/master/manifest.jps" // Force new instance
+    settings = {
+        branch = "main"
+    }
+}
+
+resource "hidora_deployment" "test-deploy" {
+    env_name = "${hidora_create_env.test-res.id}"
+    nodegroup = "cp"
+    context = "ROOT"
+    repository {
+        url = "https://github.com/HidoraSwiss/example-app.git"
+        branch = "main" // Can be update
+    }
+    hooks {
+        post_deploy = "pip install -r requirements.txt"
+    }
+    triggers = {
+        version = "1" // Redeploy when changed
+    }
+}
+
+resource "hidora_endpoint" "test-endpoint" {
+    env_name = "${hidora_create_env.test-res.id}"
+    node_id = 123456 // Force new instance
+    name = "postgres"
+    private_port = 5432
+    protocol = "TCP"
+}
+
+output "test-public-ips" {
+    value = "${hidora_create_env.test-res.nodes.0.extip_addresses}"
+}
+
+output "test-ssh-commands" {
+    value = "${formatlist("ssh %s@%s -p %d", hidora_create_env.test-res.node_instances.*.ssh_user, hidora_create_env.test-res.node_instances.*.ssh_host, hidora_create_env.test-res.node_instances.*.ssh_port)}"
+}
+
+resource "hidora_firewall_rule" "test-firewall" {
+    env_name = "${hidora_create_env.test-res.id}"
+    nodegroup = "cp"
+    direction = "INPUT"
+    name = "office"
+    protocol = "TCP"
+    ports = "22,8000-8080"
+    src = "192.0.2.0/24"
+    action = "ALLOW"
+    priority = 100
+}
+
+data "hidora_ssh_keys" "test-keys" {}
+
+resource "hidora_ssh_key" "test-key" {
+    title = "laptop"
+    public_key = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIP0VKd2FuUlZQMz44GWdaqPA4eEiPv2cklHJ4hrPcqGy user@laptop" // Force new instance
+}
+
+resource "hidora_cron" "test-cron" {
+    env_name = "${hidora_create_env.test-res.id}"
+    nodegroup = "cp"
+    name = "cleanup"
+    schedule = "0 3 * * mon-fri" // Validated at plan time
+    command = "find /tmp -mtime +7 -delete"
+}
+
+resource "hidora_node_file" "test-file" {
+    env_name = "${hidora_create_env.test-res.id}"
+    nodegroup = "cp"
+    path = "/etc/php.d/custom.ini" // Force new instance
+    content = "memory_limit = 256M\n"
+    restart = true
+}
+
+resource "hidora_exec" "test-exec" {
+    env_name = "${hidora_create_env.test-res.id}"
+    nodegroup = "cp"
+    command = "pip install -r /var/www/webroot/ROOT/requirements.txt"
+    triggers = {
+        file = "${hidora_node_file.test-file.content_hash}" // Run again when changed
+    }
+}
+
+resource "hidora_node_restart" "test-restart" {
+    env_name = "${hidora_create_env.test-res.id}"
+    nodegroup = "cp"
+    triggers = {
+        file = "${hidora_node_file.test-file.content_hash}" // Restart when changed
+    }
+}
+
+resource "hidora_backup" "test-backup" {
+    env_name = "${hidora_create_env.test-res.id}"
+    nodegroup = "cp"
+    storage_env = "env-backup-storage"
+    schedule = "0 2 * * *" // Can be update
+    retention = 7 // Can be update
+}
+
+data "hidora_backups" "test-backups" {
+    env_name = "${hidora_create_env.test-res.id}"
+    storage_env = "env-backup-storage"
+}
+
+resource "hidora_env_share" "test-share" {
+    env_name = "${hidora_create_env.test-res.id}"
+    email = "developer@example.com"
+    role = "viewer" // Can be update
+}
+
+data "hidora_node_types" "test-node-types" {
+    type = "cp"
+}
+
+output "test-nodetypes" {
+    value = "${data.hidora_node_types.test-node-types.node_types}"
+}
+
+data "hidora_account" "test-account" {}
+
+output "test-balance" {
+    value = "${data.hidora_account.test-account.balance}"
+}
+
+output "test-monthly-cost" {
+    value = "${hidora_create_env.test-res.estimated_monthly_cost}"
+}
+
+data "hidora_env_billing" "test-billing" {
+    env_name = "${hidora_create_env.test-res.id}"
+    start_date = "2022-01-01"
+    end_date = "2022-01-31"
+}
+
+output "test-billing" {
+    value = "${data.hidora_env_billing.test-billing.total_cost}"
+}
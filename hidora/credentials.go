@@ -0,0 +1,309 @@
+package hidora
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	CREDENTIALS_DEFAULT_FILE    string = "~/.hidora/credentials"
+	CREDENTIALS_DEFAULT_PROFILE string = "default"
+)
+
+// Environment variables read when the provider configuration doesn't set
+// the fields
+var credentials_env_vars = map[string]string{
+	"host":         "HIDORA_HOST",
+	"username":     "HIDORA_USERNAME",
+	"password":     "HIDORA_PASSWORD",
+	"access_token": "HIDORA_TOKEN",
+	"token_file":   "HIDORA_TOKEN_FILE",
+}
+
+// Credentials found in one step of the chain
+type Credentials struct {
+	Host        string
+	Username    string
+	Password    string
+	AccessToken string
+	Source      string
+}
+
+// Whether the step of the chain can authenticate
+func (c *Credentials) isComplete() bool {
+	return c.AccessToken != "" || (c.Username != "" && c.Password != "")
+}
+
+// Resolve the credentials from the provider configuration, then HIDORA_*
+// environment variables, then a profile of the credentials file. An access
+// token is taken from the first step which sets it, username and password
+// can come from different steps, e.g. username in the configuration and
+// HIDORA_PASSWORD. The host is the one of the first step which sets it; a
+// step can't pair its credentials with the host of another platform.
+func resolveCredentials(d *schema.ResourceData) (*Credentials, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	values := func(get func(field string) string) map[string]string {
+		step := make(map[string]string)
+		for field := range credentials_env_vars {
+			step[field] = get(field)
+		}
+		return step
+	}
+
+	config_step := values(func(field string) string {
+		return d.Get(field).(string)
+	})
+	env_step := values(func(field string) string {
+		return os.Getenv(credentials_env_vars[field])
+	})
+
+	credentials_file := firstNonEmpty(d.Get("credentials_file").(string), os.Getenv("HIDORA_CREDENTIALS_FILE"), CREDENTIALS_DEFAULT_FILE)
+	profile := firstNonEmpty(d.Get("profile").(string), os.Getenv("HIDORA_PROFILE"), CREDENTIALS_DEFAULT_PROFILE)
+	file_step, err := readCredentialsProfile(credentials_file, profile)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to read credentials file",
+			Detail:   err.Error(),
+		})
+		return nil, diags
+	}
+
+	if file_step == nil && profile != CREDENTIALS_DEFAULT_PROFILE {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Credentials profile not found",
+			Detail:   fmt.Sprintf("profile %q isn't in %s", profile, credentials_file),
+		})
+	}
+
+	steps := []struct {
+		source string
+		values map[string]string
+	}{
+		{"provider configuration", config_step},
+		{"HIDORA_* environment variables", env_step},
+		{fmt.Sprintf("profile %q of %s", profile, credentials_file), file_step},
+	}
+
+	credentials := &Credentials{}
+	host_source := ""
+	ignored_hosts := []string{}
+	username_source := ""
+	password_source := ""
+	for _, step := range steps {
+		if step.values == nil {
+			continue
+		}
+		step_host := step.values["host"]
+		if credentials.isComplete() {
+			// A later step only gives the host when it has no credentials
+			// for another platform
+			if credentials.Host == "" && step_host != "" {
+				if hasCredentials(step.values) {
+					ignored_hosts = append(ignored_hosts, step.source)
+				} else {
+					credentials.Host = step_host
+					host_source = step.source
+				}
+			}
+			continue
+		}
+
+		access_token := step.values["access_token"]
+		if access_token == "" && step.values["token_file"] != "" {
+			token, err := ioutil.ReadFile(expandHomeDir(step.values["token_file"]))
+			if err != nil {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  "Unable to read token file",
+					Detail:   fmt.Sprintf("token_file from %s: %s", step.source, err),
+				})
+				return nil, diags
+			}
+			access_token = strings.TrimSpace(string(token))
+		}
+		// Only the username or the password missing in the earlier steps
+		// is taken, and not from a step setting another account. An access
+		// token ends the chain.
+		step_username := ""
+		if credentials.Username == "" && (step.values["password"] == "" || credentials.Password == "" || step.values["password"] == credentials.Password) {
+			step_username = step.values["username"]
+		}
+		step_password := ""
+		if credentials.Password == "" && (step.values["username"] == "" || credentials.Username == "" || step.values["username"] == credentials.Username) {
+			step_password = step.values["password"]
+		}
+		if access_token == "" && step_username == "" && step_password == "" {
+			if credentials.Host == "" && step_host != "" {
+				credentials.Host = step_host
+				host_source = step.source
+			}
+			continue
+		}
+		if step_host != "" && credentials.Host != "" && step_host != credentials.Host {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Credentials of another host",
+				Detail:   fmt.Sprintf("credentials from %s are for %s but host is %s from %s", step.source, step_host, credentials.Host, host_source),
+			})
+			return nil, diags
+		}
+		if credentials.Host == "" && step_host != "" {
+			credentials.Host = step_host
+			host_source = step.source
+		}
+
+		if access_token != "" {
+			if credentials.Username != "" || credentials.Password != "" {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Warning,
+					Summary:  "Incomplete credentials ignored",
+					Detail:   fmt.Sprintf("username and password must be set together, %s only sets one of them and access token of %s is used", firstNonEmpty(username_source, password_source), step.source),
+				})
+			}
+			credentials.Username = ""
+			credentials.Password = ""
+			credentials.AccessToken = access_token
+			credentials.Source = step.source
+			continue
+		}
+		if step_username != "" {
+			credentials.Username = step_username
+			username_source = step.source
+		}
+		if step_password != "" {
+			credentials.Password = step_password
+			password_source = step.source
+		}
+		if credentials.isComplete() {
+			credentials.Source = username_source
+			if password_source != username_source {
+				credentials.Source = fmt.Sprintf("%s (username) and %s (password)", username_source, password_source)
+			}
+		}
+	}
+
+	sources := make([]string, len(steps))
+	for i, step := range steps {
+		sources[i] = step.source
+	}
+	if credentials.Host == "" {
+		detail := fmt.Sprintf("host isn't set in %s", strings.Join(sources, ", "))
+		if len(ignored_hosts) > 0 {
+			detail += fmt.Sprintf(", host of %s is ignored as it comes with other credentials than those of %s", strings.Join(ignored_hosts, ", "), credentials.Source)
+		}
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to find host",
+			Detail:   detail,
+		})
+	}
+	if !credentials.isComplete() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to find credentials",
+			Detail:   fmt.Sprintf("access_token, token_file or username and password aren't set in %s", strings.Join(sources, ", ")),
+		})
+		if username_source != "" || password_source != "" {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Incomplete credentials ignored",
+				Detail:   fmt.Sprintf("username and password must be set together, %s only sets one of them", firstNonEmpty(username_source, password_source)),
+			})
+		}
+	}
+	if diags.HasError() {
+		return nil, diags
+	}
+	return credentials, diags
+}
+
+// Whether a step sets any of the credentials fields
+func hasCredentials(values map[string]string) bool {
+	for _, field := range []string{"username", "password", "access_token", "token_file"} {
+		if values[field] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Read a profile of an INI credentials file:
+//
+//	[default]
+//	host = app.hidora.com
+//	access_token = ...
+//
+// A missing file or profile isn't an error, nil is returned.
+func readCredentialsProfile(path string, profile string) (map[string]string, error) {
+	file, err := os.Open(expandHomeDir(path))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var values map[string]string
+	current_profile := ""
+	scanner := bufio.NewScanner(file)
+	for line_number := 1; scanner.Scan(); line_number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current_profile = strings.TrimSpace(line[1 : len(line)-1])
+			if current_profile == profile && values == nil {
+				values = make(map[string]string)
+			}
+			continue
+		}
+		key_value := strings.SplitN(line, "=", 2)
+		if len(key_value) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected key = value, got: %s", path, line_number, line)
+		}
+		if current_profile != profile {
+			continue
+		}
+		key := strings.TrimSpace(key_value[0])
+		if _, ok := credentials_env_vars[key]; !ok {
+			return nil, fmt.Errorf("%s:%d: unknown key %s", path, line_number, key)
+		}
+		values[key] = strings.TrimSpace(key_value[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// Replace a leading ~ by the home directory of the user
+func expandHomeDir(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,263 @@
+package hidora
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const credentials_test_file = `
+# Profiles of the tests
+[default]
+host = app.hidora.com
+access_token = file-token
+
+[staging]
+host = app.staging.example.com
+username = staging@example.com
+password = staging-password
+
+[host-only]
+host = app.other.example.com
+`
+
+func TestResolveCredentials(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   map[string]interface{}
+		env      map[string]string
+		file     bool
+		host     string
+		token    string
+		username string
+		source   string
+		errors   []string
+		warnings []string
+	}{
+		{
+			name:   "provider configuration wins",
+			config: map[string]interface{}{"host": "app.hidora.com", "access_token": "config-token"},
+			env:    map[string]string{"HIDORA_HOST": "app.hidora.com", "HIDORA_TOKEN": "env-token"},
+			file:   true,
+			host:   "app.hidora.com",
+			token:  "config-token",
+			source: "provider configuration",
+		},
+		{
+			name:     "environment variables before the credentials file",
+			env:      map[string]string{"HIDORA_HOST": "app.hidora.com", "HIDORA_USERNAME": "env@example.com", "HIDORA_PASSWORD": "env-password"},
+			file:     true,
+			host:     "app.hidora.com",
+			username: "env@example.com",
+			source:   "HIDORA_* environment variables",
+		},
+		{
+			name:   "default profile of the credentials file",
+			file:   true,
+			host:   "app.hidora.com",
+			token:  "file-token",
+			source: `profile "default"`,
+		},
+		{
+			name:     "profile of the credentials file",
+			config:   map[string]interface{}{"profile": "staging"},
+			file:     true,
+			host:     "app.staging.example.com",
+			username: "staging@example.com",
+			source:   `profile "staging"`,
+		},
+		{
+			name:   "host of the configuration with credentials of the environment",
+			config: map[string]interface{}{"host": "app.hidora.com"},
+			env:    map[string]string{"HIDORA_TOKEN": "env-token"},
+			host:   "app.hidora.com",
+			token:  "env-token",
+			source: "HIDORA_* environment variables",
+		},
+		{
+			name:   "host of the credentials file with credentials of the configuration",
+			config: map[string]interface{}{"access_token": "config-token", "profile": "host-only"},
+			file:   true,
+			host:   "app.other.example.com",
+			token:  "config-token",
+			source: "provider configuration",
+		},
+		{
+			name:     "username of the configuration with password of the environment",
+			config:   map[string]interface{}{"host": "app.hidora.com", "username": "config@example.com"},
+			env:      map[string]string{"HIDORA_PASSWORD": "env-password"},
+			host:     "app.hidora.com",
+			username: "config@example.com",
+			source:   "provider configuration (username) and HIDORA_* environment variables (password)",
+		},
+		{
+			name:     "password of the credentials file with username of the configuration",
+			config:   map[string]interface{}{"username": "staging@example.com", "profile": "staging"},
+			file:     true,
+			host:     "app.staging.example.com",
+			username: "staging@example.com",
+			source:   "provider configuration (username) and profile",
+		},
+		{
+			name:     "password of another account isn't taken",
+			config:   map[string]interface{}{"username": "config@example.com", "profile": "staging"},
+			file:     true,
+			errors:   []string{"Unable to find credentials"},
+			warnings: []string{"Incomplete credentials ignored"},
+		},
+		{
+			name:   "password of another host",
+			config: map[string]interface{}{"host": "app.hidora.com", "username": "config@example.com"},
+			env:    map[string]string{"HIDORA_HOST": "app.other.example.com", "HIDORA_PASSWORD": "env-password"},
+			errors: []string{"Credentials of another host"},
+		},
+		{
+			name:   "credentials of another host",
+			config: map[string]interface{}{"host": "app.hidora.com"},
+			env:    map[string]string{"HIDORA_HOST": "app.other.example.com", "HIDORA_TOKEN": "env-token"},
+			errors: []string{"Credentials of another host"},
+		},
+		{
+			name:   "host coming with other credentials is ignored",
+			env:    map[string]string{"HIDORA_TOKEN": "env-token"},
+			file:   true,
+			errors: []string{"Unable to find host"},
+		},
+		{
+			name:   "token file",
+			config: map[string]interface{}{"host": "app.hidora.com", "token_file": "token"},
+			host:   "app.hidora.com",
+			token:  "token-of-the-file",
+			source: "provider configuration",
+		},
+		{
+			name:     "incomplete credentials are skipped",
+			env:      map[string]string{"HIDORA_USERNAME": "env@example.com"},
+			file:     true,
+			host:     "app.hidora.com",
+			token:    "file-token",
+			source:   `profile "default"`,
+			warnings: []string{"Incomplete credentials ignored"},
+		},
+		{
+			name:     "missing profile",
+			config:   map[string]interface{}{"host": "app.hidora.com", "access_token": "config-token", "profile": "missing"},
+			file:     true,
+			host:     "app.hidora.com",
+			token:    "config-token",
+			source:   "provider configuration",
+			warnings: []string{"Credentials profile not found"},
+		},
+		{
+			name:   "missing credentials file",
+			config: map[string]interface{}{"host": "app.hidora.com", "access_token": "config-token"},
+			host:   "app.hidora.com",
+			token:  "config-token",
+			source: "provider configuration",
+		},
+		{
+			name:   "nothing found",
+			errors: []string{"Unable to find host", "Unable to find credentials"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			// Neither the variables nor the credentials file of the user
+			// must be read
+			t.Setenv("HOME", dir)
+			for _, env_var := range credentials_env_vars {
+				t.Setenv(env_var, "")
+			}
+			t.Setenv("HIDORA_CREDENTIALS_FILE", "")
+			t.Setenv("HIDORA_PROFILE", "")
+			for key, value := range c.env {
+				t.Setenv(key, value)
+			}
+
+			config := map[string]interface{}{}
+			for key, value := range c.config {
+				config[key] = value
+			}
+			config["credentials_file"] = filepath.Join(dir, "credentials")
+			if c.file {
+				if err := ioutil.WriteFile(filepath.Join(dir, "credentials"), []byte(credentials_test_file), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if token_file, ok := config["token_file"].(string); ok {
+				config["token_file"] = filepath.Join(dir, token_file)
+				if err := ioutil.WriteFile(filepath.Join(dir, token_file), []byte("token-of-the-file\n"), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			d := schema.TestResourceDataRaw(t, Provider().Schema, config)
+			credentials, diags := resolveCredentials(d)
+
+			errors, warnings := credentialsDiagsSummaries(diags)
+			if strings.Join(errors, ", ") != strings.Join(c.errors, ", ") {
+				t.Fatalf("expected errors %q, got %v", c.errors, diags)
+			}
+			if strings.Join(warnings, ", ") != strings.Join(c.warnings, ", ") {
+				t.Fatalf("expected warnings %q, got %v", c.warnings, diags)
+			}
+			if len(c.errors) > 0 {
+				if credentials != nil {
+					t.Fatalf("expected no credentials, got %+v", credentials)
+				}
+				return
+			}
+			if credentials.Host != c.host ||
+				credentials.AccessToken != c.token ||
+				credentials.Username != c.username ||
+				!strings.HasPrefix(credentials.Source, c.source) {
+				t.Fatalf("expected host %q, token %q, username %q from %q, got %+v",
+					c.host, c.token, c.username, c.source, credentials)
+			}
+		})
+	}
+}
+
+func TestResolveCredentialsNothingFoundDetail(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	for _, env_var := range credentials_env_vars {
+		t.Setenv(env_var, "")
+	}
+	t.Setenv("HIDORA_CREDENTIALS_FILE", "")
+	t.Setenv("HIDORA_PROFILE", "")
+
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{})
+	_, diags := resolveCredentials(d)
+
+	if len(diags) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %v", diags)
+	}
+	// Every step of the chain is listed
+	for _, source := range []string{"provider configuration", "HIDORA_* environment variables", `profile "default" of ~/.hidora/credentials`} {
+		for _, diagnostic := range diags {
+			if !strings.Contains(diagnostic.Detail, source) {
+				t.Fatalf("expected %q in the detail of %q, got %s", source, diagnostic.Summary, diagnostic.Detail)
+			}
+		}
+	}
+}
+
+// Summaries of the errors and of the warnings
+func credentialsDiagsSummaries(diags diag.Diagnostics) ([]string, []string) {
+	var errors, warnings []string
+	for _, d := range diags {
+		if d.Severity == diag.Error {
+			errors = append(errors, d.Summary)
+		} else {
+			warnings = append(warnings, d.Summary)
+		}
+	}
+	return errors, warnings
+}
@@ -0,0 +1,190 @@
+package hidora
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	API_USERS_ACCOUNT_GETUSERINFO_ENDPOINT  string = "users/account/rest/getuserinfo"
+	API_BILLING_ACCOUNT_GETACCOUNT_ENDPOINT string = "billing/account/rest/getaccount"
+	API_BILLING_ACCOUNT_GETQUOTAS_ENDPOINT  string = "billing/account/rest/getquotas"
+)
+
+// Quotas of the account, by attribute of the quotas block
+var account_quotas = map[string]string{
+	"max_cloudlets_per_node": "environment.maxcloudletsperrec",
+	"max_cloudlets":          "environment.maxcloudlets",
+	"max_nodes":              "environment.maxnodescount",
+	"max_envs":               "environment.maxcount",
+	"max_extips":             "environment.externalip.maxcount",
+	"max_disk":               "disk.limitation",
+}
+
+func dataSourceHidoraAccount() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceJelasticAccountRead,
+		Schema: map[string]*schema.Schema{
+			"uid": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "User ID of the account",
+			},
+			"email": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "",
+			},
+			"balance": {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "Balance of the account, bonuses included",
+			},
+			"group": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Billing group of the account (trial, billing...)",
+			},
+			"quotas": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Limits of the account, -1 when unlimited and 0 when the platform doesn't return the limit",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"max_cloudlets_per_node": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "",
+						},
+						"max_cloudlets": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Maximum number of cloudlets by environment",
+						},
+						"max_nodes": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Maximum number of nodes by environment",
+						},
+						"max_envs": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "",
+						},
+						"max_extips": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Maximum number of public IPs by environment",
+						},
+						"max_disk": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Maximum disk space by node in MB",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceJelasticAccountRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	user_info, err := m.doJelasticRequest(API_USERS_ACCOUNT_GETUSERINFO_ENDPOINT, url.Values{})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get user informations",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	uid, _ := user_info["uid"].(float64)
+	_ = d.Set("uid", int(uid))
+	_ = d.Set("email", user_info["email"])
+
+	account, err := m.doJelasticRequest(API_BILLING_ACCOUNT_GETACCOUNT_ENDPOINT, url.Values{
+		"uid": {strconv.Itoa(int(uid))},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get billing account",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	balance, _ := account["balance"].(float64)
+	bonus, _ := account["bonus"].(float64)
+	_ = d.Set("balance", balance+bonus)
+	// group is an object on recent platforms
+	switch group := account["group"].(type) {
+	case string:
+		_ = d.Set("group", group)
+	case map[string]interface{}:
+		_ = d.Set("group", group["name"])
+	}
+
+	quotas, err := getAccountQuotas(m)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get quotas",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	_ = d.Set("quotas", []interface{}{quotas})
+
+	d.SetId(fmt.Sprintf("%d", int(uid)))
+
+	return diags
+}
+
+// Get the limits of the account by attribute of the quotas block. Limits
+// the platform doesn't return are left out, -1 is unlimited.
+func getAccountQuotas(m *Client) (map[string]interface{}, error) {
+	quota_names := []string{}
+	for _, quota_name := range account_quotas {
+		quota_names = append(quota_names, quota_name)
+	}
+
+	result, err := m.doJelasticRequest(API_BILLING_ACCOUNT_GETQUOTAS_ENDPOINT, url.Values{
+		"quotasnames": {strings.Join(quota_names, ";")},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]int)
+	array, _ := result["array"].([]interface{})
+	for _, quota := range array {
+		quota_map, _ := quota.(map[string]interface{})
+		quota_infos, _ := quota_map["quota"].(map[string]interface{})
+		quota_name, _ := quota_infos["name"].(string)
+		value, ok := quota_map["value"].(float64)
+		if !ok {
+			continue
+		}
+		values[quota_name] = int(value)
+	}
+
+	quotas := make(map[string]interface{})
+	for attribute, quota_name := range account_quotas {
+		if value, ok := values[quota_name]; ok {
+			quotas[attribute] = value
+		}
+	}
+	return quotas, nil
+}
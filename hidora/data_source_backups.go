@@ -0,0 +1,129 @@
+package hidora
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+type BackupSnapshot struct {
+	Id       string   `json:"id"`
+	Shortid  string   `json:"short_id"`
+	Time     string   `json:"time"`
+	Hostname string   `json:"hostname"`
+	Tags     []string `json:"tags"`
+}
+
+func dataSourceHidoraBackups() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceJelasticBackupsRead,
+		Schema: map[string]*schema.Schema{
+			"env_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the backed up environment",
+			},
+			"storage_env": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the storage environment receiving the backups",
+			},
+			"backups": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Backup points, from the oldest to the newest",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"short_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"time": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"hostname": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"tags": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceJelasticBackupsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	env_name := d.Get("env_name").(string)
+	storage_env := d.Get("storage_env").(string)
+
+	// The backup add-on stores a restic repository by environment,
+	// protected by the name of the environment
+	command := fmt.Sprintf("RESTIC_PASSWORD=%s restic -r %s snapshots --json", shellQuote(env_name), shellQuote(BACKUP_STORAGE_REPOSITORY_ROOT+env_name))
+	results, err := execCommand(m, storage_env, BACKUP_STORAGE_NODEGROUP, 0, command)
+	if err != nil || len(results) == 0 || results[0]["exit_code"].(int) != 0 {
+		detail := fmt.Sprintf("No output from %s", storage_env)
+		if err != nil {
+			detail = err.Error()
+		} else if len(results) > 0 {
+			detail = results[0]["stderr"].(string)
+		}
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Unable to list backups of %s", env_name),
+			Detail:   detail,
+		})
+		return diags
+	}
+
+	var snapshots []BackupSnapshot
+	if err := json.Unmarshal([]byte(results[0]["stdout"].(string)), &snapshots); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Unable to list backups of %s", env_name),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	flatten_backups := []map[string]interface{}{}
+	for _, snapshot := range snapshots {
+		flatten_backups = append(flatten_backups, map[string]interface{}{
+			"id":       snapshot.Id,
+			"short_id": snapshot.Shortid,
+			"time":     snapshot.Time,
+			"hostname": snapshot.Hostname,
+			"tags":     snapshot.Tags,
+		})
+	}
+	_ = d.Set("backups", flatten_backups)
+
+	d.SetId(fmt.Sprintf("%s/%s", storage_env, env_name))
+
+	return diags
+}
@@ -2,10 +2,6 @@ package hidora
 
 import (
 	"context"
-	"encoding/json"
-	"io/ioutil"
-	"net"
-	"net/http"
 	"net/url"
 	"strings"
 
@@ -95,16 +91,37 @@ func dataSourceHidoraCreateEnvironment() *schema.Resource {
 								Type: schema.TypeString,
 							},
 						},
-						"extip": { // Goal is to import the public IP
+						"extip": {
 							Type:        schema.TypeBool,
 							Computed:    true,
 							Description: "",
 						},
-						"extipv6": { // Goal is to import the public IPv6
+						"extipv6": {
 							Type:        schema.TypeBool,
 							Computed:    true,
 							Description: "",
 						},
+						"extip_count": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of public IPv4 of the node",
+						},
+						"extip_addresses": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "Public IPv4 addresses of the node",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"extipv6_addresses": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "Public IPv6 addresses of the node",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
 						"fixedcloudlets": {
 							Type:        schema.TypeInt,
 							Computed:    true,
@@ -187,6 +204,65 @@ func dataSourceHidoraCreateEnvironment() *schema.Resource {
 				Computed:    true,
 				Description: "Define which group is chosen for the environment",
 			},
+			"node_instances": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Nodes created by the platform for every nodes block",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "",
+						},
+						"nodegroup": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"intip": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Internal IP address of the node",
+						},
+						"url": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"adminurl": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"status": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Status code of the node, 1 when running",
+						},
+						"ismaster": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "",
+						},
+						"ssh_user": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "User to connect to the node through the SSH gateway",
+						},
+						"ssh_host": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Host of the SSH gateway, empty when the provider can't guess it (see ssh_gateway)",
+						},
+						"ssh_port": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Port of the SSH gateway",
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -194,51 +270,19 @@ func dataSourceHidoraCreateEnvironment() *schema.Resource {
 func dataSourceJelasticCreateEnvironmentRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	// Statement of m and type assertion with *CLient
 	m := meta.(*Client)
-	// Statement of http client to do API requests
-	client := m.HTTPClient
-	session := m.Token
 
 	// Declare diag variable for debugging
 	var diags diag.Diagnostics
 
-	// Define REST URL
-	u := *m.BaseUrl
-	u.Path += API_ENV_CONTROL_GETENVINFO_ENDPOINT
-	urlStr := u.String()
-
-	var req_config JelasticRequest = JelasticRequest{
-		Method:  http.MethodPost,
-		Headers: client_headers,
-	}
-
-	req_config.Query = url.Values{
+	result, err := m.doJelasticRequest(API_ENV_CONTROL_GETENVINFO_ENDPOINT, url.Values{
 		"envName": {d.Get("id").(string)},
-		"session": {session},
 		"lazy":    {"false"}, // Need all informations
-	}
-	req_config.Body = strings.NewReader(req_config.Query.Encode())
-	req, _ := http.NewRequest(req_config.Method, urlStr, req_config.Body)
-	req.Header = req_config.Headers
-
-	resp, err := client.Do(req)
+	})
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Unable to get environment informations",
-			Detail:   "Can't get informations about environment because of bad envName",
-		})
-		return diags
-	}
-	defer resp.Body.Close()
-
-	body, _ := ioutil.ReadAll(resp.Body)
-	var result map[string]interface{}
-	json.Unmarshal(body, &result)
-	if result["result"].(float64) != 0 {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "Unable to get environment informations",
-			Detail:   "Can't get informations about environment because of bad envName",
+			Detail:   err.Error(),
 		})
 		return diags
 	}
@@ -256,6 +300,7 @@ func dataSourceJelasticCreateEnvironmentRead(ctx context.Context, d *schema.Reso
 	// return diags
 	_ = d.Set("nodes", flattenCreateEnvironmentNodesData(&result_nodes, &result_nodegroups))
 	_ = d.Set("owneruid", result_env["ownerUid"].(float64))
+	_ = d.Set("node_instances", flattenCreateEnvironmentNodeInstances(result_nodes, result_env["ownerUid"].(float64), m.SshGateway))
 	_ = d.Set("envgroups", result_envgroups[0].(string)) // envgroups is not a array, can fix later
 
 	d.SetId(d.Get("id").(string))
@@ -288,20 +333,12 @@ func flattenCreateEnvironmentNodesData(nodes *[]interface{}, nodegroups *[]inter
 			envsmap[envcut[0]] = envcut[1]
 		}
 		flatten_node["env"] = envsmap // search value in customitem -> dockerManifest -> env
-		extiplist, ok := node_map["extIPs"].([]interface{})
-		if !ok {
-			flatten_node["extip"] = false
-			flatten_node["extipv6"] = false
-		} else if ok {
-			for _, extip := range extiplist {
-				net.ParseIP(extip.(string)).To4()
-				if net.ParseIP(extip.(string)).To4() != nil {
-					flatten_node["extip"] = true
-				} else if net.ParseIP(extip.(string)).To16() != nil {
-					flatten_node["extipv6"] = true
-				}
-			}
-		}
+		extipv4, extipv6 := splitNodeExtIps(node_map)
+		flatten_node["extip"] = len(extipv4) > 0
+		flatten_node["extipv6"] = len(extipv6) > 0
+		flatten_node["extip_count"] = len(extipv4)
+		flatten_node["extip_addresses"] = extipv4
+		flatten_node["extipv6_addresses"] = extipv6
 		flatten_node["fixedcloudlets"] = int(node_map["fixedCloudlets"].(float64))       // search value in customitem -> fixedCloudlets
 		flatten_node["flexiblecloudlets"] = int(node_map["flexibleCloudlets"].(float64)) // search value in customitem -> search value in customitem -> flexibleCloudlets
 		flatten_node["image"] = customitem["dockerName"].(string)                        // search value in customitem -> dockerName
@@ -0,0 +1,212 @@
+package hidora
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	API_BILLING_ACCOUNT_GETACCOUNTBILLINGHISTORYBYPERIOD_ENDPOINT string = "billing/account/rest/getaccountbillinghistorybyperiod"
+	BILLING_DATE_FORMAT                                           string = "2006-01-02"
+	BILLING_DATETIME_FORMAT                                       string = "2006-01-02 15:04:05"
+	BILLING_PERIOD                                                string = "DAY"
+)
+
+// Attribute of the costs by word of the resource types in the billing
+// history, e.g. FIXED_CLOUDLET or EXTERNAL_IP
+var billing_resource_types = map[string]string{
+	"CLOUDLET": "cloudlets_cost",
+	"DISK":     "storage_cost",
+	"STORAGE":  "storage_cost",
+	"TRAFFIC":  "traffic_cost",
+	"IP":       "ips_cost",
+	"IPV4":     "ips_cost",
+	"IPV6":     "ips_cost",
+}
+
+func dataSourceHidoraEnvBilling() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceJelasticEnvBillingRead,
+		Schema: map[string]*schema.Schema{
+			"env_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the environment",
+			},
+			"start_date": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateBillingDate,
+				Description:  "First day of the report (YYYY-MM-DD)",
+			},
+			"end_date": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateBillingDate,
+				Description:  "Last day of the report (YYYY-MM-DD), today when empty",
+			},
+			"total_cost": {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "",
+			},
+			"cloudlets_cost": {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "",
+			},
+			"storage_cost": {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "",
+			},
+			"traffic_cost": {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "",
+			},
+			"ips_cost": {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "",
+			},
+			"other_cost": {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "Cost of the resources which are not in the other categories (licenses, add-ons...)",
+			},
+			"daily_costs": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Cost of each day of the period",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"date": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"cost": {
+							Type:        schema.TypeFloat,
+							Computed:    true,
+							Description: "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceJelasticEnvBillingRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	env_name := d.Get("env_name").(string)
+	start_date, _ := time.Parse(BILLING_DATE_FORMAT, d.Get("start_date").(string))
+	end_date := time.Now().UTC()
+	if v, ok := d.GetOk("end_date"); ok {
+		end_date, _ = time.Parse(BILLING_DATE_FORMAT, v.(string))
+	}
+	if end_date.Before(start_date) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid billing period",
+			Detail:   "end_date is before start_date",
+		})
+		return diags
+	}
+
+	result, err := m.doJelasticRequest(API_BILLING_ACCOUNT_GETACCOUNTBILLINGHISTORYBYPERIOD_ENDPOINT, url.Values{
+		"starttime":  {start_date.Format(BILLING_DATETIME_FORMAT)},
+		"endtime":    {end_date.Format(BILLING_DATE_FORMAT) + " 23:59:59"},
+		"period":     {BILLING_PERIOD},
+		"timeOffset": {"0"},
+		"groupNodes": {"true"},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Unable to get billing history of %s", env_name),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	costs := map[string]float64{
+		"total_cost":     0,
+		"cloudlets_cost": 0,
+		"storage_cost":   0,
+		"traffic_cost":   0,
+		"ips_cost":       0,
+		"other_cost":     0,
+	}
+	daily_costs := []map[string]interface{}{}
+	daily_indexes := make(map[string]int)
+
+	history, _ := result["array"].([]interface{})
+	for _, item := range history {
+		item_map := item.(map[string]interface{})
+		if item_env_name, _ := item_map["envName"].(string); item_env_name != env_name {
+			continue
+		}
+		cost, _ := item_map["cost"].(float64)
+		resource_type, _ := item_map["resourceType"].(string)
+		costs["total_cost"] += cost
+		costs[billingResourceAttribute(resource_type)] += cost
+
+		date_time, _ := item_map["dateTime"].(string)
+		date := strings.SplitN(date_time, " ", 2)[0]
+		if i, ok := daily_indexes[date]; ok {
+			daily_costs[i]["cost"] = daily_costs[i]["cost"].(float64) + cost
+		} else {
+			daily_indexes[date] = len(daily_costs)
+			daily_costs = append(daily_costs, map[string]interface{}{
+				"date": date,
+				"cost": cost,
+			})
+		}
+	}
+
+	for attribute, cost := range costs {
+		_ = d.Set(attribute, cost)
+	}
+	_ = d.Set("daily_costs", daily_costs)
+
+	d.SetId(fmt.Sprintf("%s/%s/%s", env_name, start_date.Format(BILLING_DATE_FORMAT), end_date.Format(BILLING_DATE_FORMAT)))
+
+	return diags
+}
+
+// Attribute of the cost of a resource type of the billing history, from the
+// first of its words, singular or plural, which is a known resource
+func billingResourceAttribute(resource_type string) string {
+	words := strings.FieldsFunc(strings.ToUpper(resource_type), func(r rune) bool {
+		return (r < 'A' || r > 'Z') && (r < '0' || r > '9')
+	})
+	for _, word := range words {
+		if attribute, ok := billing_resource_types[word]; ok {
+			return attribute
+		}
+		if attribute, ok := billing_resource_types[strings.TrimSuffix(word, "S")]; ok {
+			return attribute
+		}
+	}
+	return "other_cost"
+}
+
+func validateBillingDate(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := time.Parse(BILLING_DATE_FORMAT, v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q must be a date formatted as YYYY-MM-DD, got: %s", k, v.(string)))
+	}
+	return
+}
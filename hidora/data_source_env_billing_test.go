@@ -0,0 +1,33 @@
+package hidora
+
+import "testing"
+
+func TestBillingResourceAttribute(t *testing.T) {
+	cases := []struct {
+		resource_type string
+		attribute     string
+	}{
+		{"FIXED_CLOUDLET", "cloudlets_cost"},
+		{"flexible_cloudlets", "cloudlets_cost"},
+		{"DISK", "storage_cost"},
+		{"STORAGE", "storage_cost"},
+		{"EXTERNAL_TRAFFIC", "traffic_cost"},
+		{"IP", "ips_cost"},
+		{"EXTERNAL_IP", "ips_cost"},
+		{"PUBLIC IPS", "ips_cost"},
+		{"IPV6", "ips_cost"},
+		{"SCRIPT", "other_cost"},
+		{"SHIPPING", "other_cost"},
+		{"MULTIPLE", "other_cost"},
+		{"LICENSE", "other_cost"},
+		{"", "other_cost"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.resource_type, func(t *testing.T) {
+			if attribute := billingResourceAttribute(c.resource_type); attribute != c.attribute {
+				t.Fatalf("expected %s, got %s", c.attribute, attribute)
+			}
+		})
+	}
+}
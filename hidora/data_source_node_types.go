@@ -0,0 +1,189 @@
+package hidora
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/agext/levenshtein"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	API_ENV_CONTROL_GETTEMPLATES_ENDPOINT string = "environment/control/rest/gettemplates"
+	NODETYPE_DOCKER                       string = "docker"
+	NODETYPE_SUGGESTIONS_MAX              int    = 3
+	NODETYPE_SUGGESTIONS_DISTANCE         int    = 3
+)
+
+func dataSourceHidoraNodeTypes() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceJelasticNodeTypesRead,
+		Schema: map[string]*schema.Schema{
+			"type": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Keep only the stacks of this type (cp, bl, sqldb, nosqldb, cache, storage...)",
+			},
+			"node_types": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Stacks and engines available on the platform",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"nodetype": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Value of nodetype in hidora_create_env",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"engine": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"docker_image": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"versions": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceJelasticNodeTypesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	node_types, err := getNodeTypes(m)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get node types",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	node_type_filter := d.Get("type").(string)
+	flatten_node_types := []map[string]interface{}{}
+	for _, node_type := range node_types {
+		if node_type_filter != "" && node_type["type"] != node_type_filter {
+			continue
+		}
+		flatten_node_types = append(flatten_node_types, node_type)
+	}
+	_ = d.Set("node_types", flatten_node_types)
+
+	d.SetId(fmt.Sprintf("%s/%s", m.BaseUrl.Host, node_type_filter))
+
+	return diags
+}
+
+// List the templates of the platform. The list is kept on the client as it
+// doesn't change during a run and is used to validate every plan.
+func getNodeTypes(m *Client) ([]map[string]interface{}, error) {
+	m.node_types_lock.Lock()
+	defer m.node_types_lock.Unlock()
+
+	if m.node_types != nil {
+		return m.node_types, nil
+	}
+
+	result, err := m.doJelasticRequest(API_ENV_CONTROL_GETTEMPLATES_ENDPOINT, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	node_types := []map[string]interface{}{}
+	templates, _ := result["array"].([]interface{})
+	for _, template := range templates {
+		template_map := template.(map[string]interface{})
+		node_type := make(map[string]interface{})
+		node_type["nodetype"], _ = template_map["nodeType"].(string)
+		node_type["name"], _ = template_map["displayName"].(string)
+		node_type["type"], _ = template_map["nodeMission"].(string)
+		node_type["engine"], _ = template_map["engineType"].(string)
+		node_type["docker_image"], _ = template_map["dockerName"].(string)
+		versions := []string{}
+		tags, _ := template_map["tags"].([]interface{})
+		for _, tag := range tags {
+			if version, ok := tag.(string); ok {
+				versions = append(versions, version)
+			}
+		}
+		node_type["versions"] = versions
+		node_types = append(node_types, node_type)
+	}
+	sort.Slice(node_types, func(i, j int) bool {
+		return node_types[i]["nodetype"].(string) < node_types[j]["nodetype"].(string)
+	})
+
+	m.node_types = node_types
+	return node_types, nil
+}
+
+// Check that nodetype is available on the platform, the error suggests the
+// closest node types when it's not.
+func validateNodeType(node_types []map[string]interface{}, nodetype string) error {
+	if nodetype == NODETYPE_DOCKER {
+		return nil
+	}
+
+	type suggestion struct {
+		nodetype string
+		distance int
+	}
+	var suggestions []suggestion
+	for _, node_type := range node_types {
+		candidate := node_type["nodetype"].(string)
+		if candidate == nodetype {
+			return nil
+		}
+		distance := levenshtein.Distance(nodetype, candidate, nil)
+		if distance <= NODETYPE_SUGGESTIONS_DISTANCE || strings.HasPrefix(candidate, nodetype) {
+			suggestions = append(suggestions, suggestion{candidate, distance})
+		}
+	}
+
+	if len(suggestions) == 0 {
+		return fmt.Errorf("nodetype %q isn't available, see the hidora_node_types data source", nodetype)
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].distance < suggestions[j].distance
+	})
+	if len(suggestions) > NODETYPE_SUGGESTIONS_MAX {
+		suggestions = suggestions[:NODETYPE_SUGGESTIONS_MAX]
+	}
+	names := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		names[i] = fmt.Sprintf("%q", s.nodetype)
+	}
+	return fmt.Errorf("nodetype %q isn't available, did you mean %s?", nodetype, strings.Join(names, " or "))
+}
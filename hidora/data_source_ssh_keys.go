@@ -0,0 +1,71 @@
+package hidora
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceHidoraSshKeys() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceJelasticSshKeysRead,
+		Schema: map[string]*schema.Schema{
+			"keys": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Public SSH keys of the account",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "",
+						},
+						"title": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"public_key": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"fingerprint": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceJelasticSshKeysRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	keys, err := getSshKeys(m)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get SSH keys",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	_ = d.Set("keys", keys)
+
+	// always run
+	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+
+	return diags
+}
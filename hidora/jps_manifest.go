@@ -0,0 +1,121 @@
+package hidora
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v2"
+)
+
+//go:embed jps_schema.json
+var jps_schema_json string
+
+// JSON Schema of the JPS format, only the parts checked before install
+var jps_schema = gojsonschema.NewStringLoader(jps_schema_json)
+
+// Parse a JPS manifest written in JSON or YAML
+func parseJpsManifest(manifest string) (map[string]interface{}, error) {
+	var result map[string]interface{}
+
+	// JSON indented with tabs is not valid YAML, so try JSON first
+	if strings.HasPrefix(strings.TrimSpace(manifest), "{") {
+		if err := json.Unmarshal([]byte(manifest), &result); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %s", err)
+		}
+		return result, nil
+	}
+
+	var yaml_result interface{}
+	if err := yaml.Unmarshal([]byte(manifest), &yaml_result); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %s", err)
+	}
+	result, ok := convertYamlValue(yaml_result).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("manifest must be an object")
+	}
+	return result, nil
+}
+
+// yaml.v2 decodes objects as map[interface{}]interface{} which can't be
+// validated as JSON, keys are converted to strings recursively
+func convertYamlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			result[fmt.Sprint(k)] = convertYamlValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = convertYamlValue(item)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// Validate a JPS manifest against the JPS schema, one error is returned by
+// offending path of the manifest
+func validateJpsManifest(manifest string) []error {
+	document, err := parseJpsManifest(manifest)
+	if err != nil {
+		return []error{err}
+	}
+
+	result, err := gojsonschema.Validate(jps_schema, gojsonschema.NewGoLoader(document))
+	if err != nil {
+		return []error{err}
+	}
+
+	// oneOf and anyOf errors only repeat the errors of their branches,
+	// they are kept when nothing more precise is reported
+	var errs []error
+	var combinator_errs []error
+	for _, result_error := range result.Errors() {
+		err := fmt.Errorf("%s: %s", result_error.Field(), result_error.Description())
+		switch result_error.Type() {
+		case "number_one_of", "number_any_of":
+			combinator_errs = append(combinator_errs, err)
+		default:
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return combinator_errs
+	}
+	return errs
+}
+
+// ValidateDiagFunc of the inline manifest attributes
+func validateJpsManifestDiag(value interface{}, path cty.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	manifest, ok := value.(string)
+	if !ok {
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       "Wrong value type",
+			Detail:        "Value type has to be a string type.",
+			AttributePath: path,
+		})
+		return diags
+	}
+
+	for _, err := range validateJpsManifest(manifest) {
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       "Invalid JPS manifest",
+			Detail:        err.Error(),
+			AttributePath: path,
+		})
+	}
+	return diags
+}
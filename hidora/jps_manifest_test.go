@@ -0,0 +1,177 @@
+package hidora
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseJpsManifest(t *testing.T) {
+	cases := []struct {
+		name     string
+		manifest string
+		err      string
+	}{
+		{
+			name:     "yaml",
+			manifest: "type: install\nname: app\nnodes:\n  - nodeType: nginx\n",
+		},
+		{
+			name:     "json indented with tabs",
+			manifest: "{\n\t\"type\": \"install\",\n\t\"name\": \"app\"\n}",
+		},
+		{
+			name:     "invalid json",
+			manifest: "{\"type\": \"install\",}",
+			err:      "invalid JSON",
+		},
+		{
+			name:     "not an object",
+			manifest: "- type: install\n- name: app\n",
+			err:      "manifest must be an object",
+		},
+		{
+			name:     "scalar",
+			manifest: "install",
+			err:      "manifest must be an object",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			document, err := parseJpsManifest(c.manifest)
+			if c.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if document["type"] != "install" || document["name"] != "app" {
+					t.Fatalf("unexpected document: %v", document)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("expected error %q, got %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestParseJpsManifestYamlKeys(t *testing.T) {
+	document, err := parseJpsManifest("type: install\nname: app\nglobals:\n  1: one\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	globals, ok := document["globals"].(map[string]interface{})
+	if !ok || globals["1"] != "one" {
+		t.Fatalf("nested keys must be strings, got %#v", document["globals"])
+	}
+}
+
+func TestValidateJpsManifest(t *testing.T) {
+	cases := []struct {
+		name     string
+		manifest string
+		// Field paths of the expected errors, none when the manifest is valid
+		paths []string
+	}{
+		{
+			name: "valid yaml",
+			manifest: `
+type: install
+name: WordPress
+nodes:
+  - nodeType: nginxphp
+    count: 1
+  - image: mariadb:10
+    nodeGroup: sqldb
+settings:
+  fields:
+    - name: email
+      type: string
+onInstall:
+  - cmd[cp]: echo ok
+`,
+		},
+		{
+			name:     "valid json indented with tabs",
+			manifest: "{\n\t\"type\": \"update\",\n\t\"name\": \"Addon\",\n\t\"nodes\": {\n\t\t\"nodeType\": \"docker\"\n\t}\n}",
+		},
+		{
+			name: "placeholders in node fields",
+			manifest: `
+type: install
+name: app
+nodes:
+  - nodeType: nginxphp
+    count: ${settings.nodes}
+    cloudlets: 8
+    fixedCloudlets: ${globals.fixed}
+    flexibleCloudlets: ${settings.flexible}
+    diskLimit: ${settings.disk}
+    extip: ${settings.extip}
+    extipv6: false
+settings:
+  fields:
+    - name: env
+      type: envname
+    - name: hosts
+      type: grid
+`,
+		},
+		{
+			name:     "text instead of a number or a placeholder",
+			manifest: "type: install\nname: app\nnodes:\n  - nodeType: nginxphp\n    count: many\n    extip: yes please\n",
+			paths:    []string{"nodes.0.count", "nodes.0.extip"},
+		},
+		{
+			name:     "unknown type",
+			manifest: "type: deploy\nname: app\n",
+			paths:    []string{"type"},
+		},
+		{
+			name:     "node without nodeType or image",
+			manifest: "type: install\nname: app\nnodes:\n  - count: 1\n",
+			paths:    []string{"nodes.0"},
+		},
+		{
+			name:     "bad settings field type",
+			manifest: "type: install\nname: app\nsettings:\n  fields:\n    - name: email\n      type: 42\n",
+			paths:    []string{"settings.fields.0.type"},
+		},
+		{
+			name:     "missing name",
+			manifest: "type: install\n",
+			paths:    []string{"(root)"},
+		},
+		{
+			name:     "not an object",
+			manifest: "- type: install\n",
+			paths:    []string{"manifest must be an object"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			errs := validateJpsManifest(c.manifest)
+			if len(c.paths) == 0 {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) == 0 {
+				t.Fatalf("expected errors on %v", c.paths)
+			}
+			for _, path := range c.paths {
+				found := false
+				for _, err := range errs {
+					if err.Error() == path || strings.HasPrefix(err.Error(), path+": ") {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("expected an error on %s, got %v", path, errs)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package hidora
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	API_ENV_SYSTEM_GETVERSION_ENDPOINT string = "environment/system/rest/getversion"
+)
+
+// Features which depend on the version of the platform
+const (
+	FEATURE_TOKEN_SCOPES  string = "token_scopes"
+	FEATURE_NODE_LABELS   string = "node_labels"
+	FEATURE_COLLABORATION string = "collaboration"
+)
+
+// Minimum version of the platform of each feature
+var platform_features = map[string]string{
+	FEATURE_TOKEN_SCOPES:  "5.7",
+	FEATURE_NODE_LABELS:   "6.0",
+	FEATURE_COLLABORATION: "6.1",
+}
+
+// HTTP transport trusting ca_bundle in addition to the system certificates
+func newTlsTransport(ca_bundle string, insecure_skip_verify bool) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	tls_config := &tls.Config{
+		InsecureSkipVerify: insecure_skip_verify,
+	}
+	if ca_bundle != "" {
+		pem, err := ioutil.ReadFile(expandHomeDir(ca_bundle))
+		if err != nil {
+			return nil, err
+		}
+		root_cas, err := x509.SystemCertPool()
+		if err != nil || root_cas == nil {
+			root_cas = x509.NewCertPool()
+		}
+		if !root_cas.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s doesn't contain any PEM certificate", ca_bundle)
+		}
+		tls_config.RootCAs = root_cas
+	}
+	transport.TLSClientConfig = tls_config
+	return transport, nil
+}
+
+// Read the version of the platform. The version stays empty when the
+// platform doesn't tell it and every feature is then considered available.
+func discoverPlatform(c *Client) {
+	result, err := c.doJelasticRequest(API_ENV_SYSTEM_GETVERSION_ENDPOINT, url.Values{})
+	if err != nil {
+		log.Printf("[WARN] Unable to get the version of the platform, every feature is enabled: %s", err)
+		return
+	}
+	c.PlatformVersion, _ = result["version"].(string)
+	log.Printf("[INFO] Platform version %s", c.PlatformVersion)
+}
+
+// Whether the platform is recent enough for a feature
+func (c *Client) supportsFeature(feature string) bool {
+	if c.PlatformVersion == "" {
+		return true
+	}
+	return compareVersions(c.PlatformVersion, platform_features[feature]) >= 0
+}
+
+// Return an error when the platform is too old for a feature
+func (c *Client) requireFeature(feature string) error {
+	if !c.supportsFeature(feature) {
+		return fmt.Errorf("%s needs platform version %s or later, %s runs %s",
+			feature, platform_features[feature], c.BaseUrl.Host, c.PlatformVersion)
+	}
+	return nil
+}
+
+// Compare dotted versions number by number, 5.9.2 is before 5.10
+func compareVersions(a string, b string) int {
+	a_parts := strings.Split(a, ".")
+	b_parts := strings.Split(b, ".")
+	for i := 0; i < len(a_parts) || i < len(b_parts); i++ {
+		a_number, b_number := 0, 0
+		if i < len(a_parts) {
+			a_number, _ = strconv.Atoi(strings.TrimFunc(a_parts[i], isNotDigit))
+		}
+		if i < len(b_parts) {
+			b_number, _ = strconv.Atoi(strings.TrimFunc(b_parts[i], isNotDigit))
+		}
+		if a_number != b_number {
+			if a_number < b_number {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func isNotDigit(r rune) bool {
+	return r < '0' || r > '9'
+}
@@ -6,20 +6,50 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 type Client struct {
 	BaseUrl    *url.URL
 	HTTPClient *http.Client
 	Token      string
+
+	// appid sent with every request and version of the platform, see
+	// discoverPlatform
+	AppId           string
+	PlatformVersion string
+
+	// Host of the SSH gateway of the nodes, empty when unknown
+	SshGateway string
+
+	// Defaults of the provider for the resources which omit them
+	DefaultRegion     string
+	DefaultEnvgroups  string
+	DefaultNodeLabels map[string]string
+
+	// Expiration and API methods of the access token, see introspectToken.
+	// AllowedMethods is nil when every method is allowed.
+	TokenExpiresAt time.Time
+	AllowedMethods map[string]bool
+
+	// Sign in again when the session expires, nil with an access token.
+	// Token is then replaced under session_lock, read it with session.
+	refreshSession func() error
+	session_lock   sync.Mutex
+
+	// Templates of the platform, see getNodeTypes
+	node_types      []map[string]interface{}
+	node_types_lock sync.Mutex
 }
 
 type JelasticRequest struct {
@@ -30,11 +60,12 @@ type JelasticRequest struct {
 }
 
 const (
-	PLATFORM_APPID                 string = "1dd8d191d38fff45e62564fcf67fdcd6" // https://docs.jelastic.com/api
-	API_PROTO                      string = "https://"
-	API_VERSION                    string = "/1.0/"
+	PLATFORM_APPID                 string = "1dd8d191d38fff45e62564fcf67fdcd6" // https://docs.jelastic.com/api, default of appid
+	API_PROTO                      string = "https://"                         // Default of scheme
+	API_VERSION                    string = "/1.0/"                            // Default of api_path
 	TOKEN_LENGTH                   int    = 40
 	API_USERS_AUTH_SIGNIN_ENDPOINT string = "users/authentication/rest/signin"
+	API_RESULT_SESSION_EXPIRED     int    = 702 // Session is unknown or expired
 )
 
 var client_headers = http.Header{
@@ -43,6 +74,31 @@ var client_headers = http.Header{
 	"Accept":         {"application/json"},
 }
 
+// Error returned by the Jelastic API when the result code is not 0
+type JelasticError struct {
+	Endpoint string
+	Result   int
+	Message  string
+}
+
+func (e *JelasticError) Error() string {
+	return fmt.Sprintf("%s failed with result %d: %s", e.Endpoint, e.Result, e.Message)
+}
+
+// Whether err is an error of the Jelastic API with one of the result codes
+func isJelasticResult(err error, results ...int) bool {
+	jelastic_err, ok := err.(*JelasticError)
+	if !ok {
+		return false
+	}
+	for _, result := range results {
+		if jelastic_err.Result == result {
+			return true
+		}
+	}
+	return false
+}
+
 // Provider
 func Provider() *schema.Provider {
 	return &schema.Provider{
@@ -50,31 +106,143 @@ func Provider() *schema.Provider {
 			"host": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("HIDORA_HOST", nil),
+				Description: "Host of the platform, HIDORA_HOST or host of the credentials file when empty",
 			},
 			"username": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("HIDORA_USERNAME", nil),
+				Description: "HIDORA_USERNAME or username of the credentials file when empty",
 			},
 			"password": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Sensitive:   true,
-				DefaultFunc: schema.EnvDefaultFunc("HIDORA_PASSWORD", nil),
+				Description: "HIDORA_PASSWORD or password of the credentials file when empty",
 			},
 			"access_token": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Sensitive:   true,
-				DefaultFunc: schema.EnvDefaultFunc("HIDORA_TOKEN", nil),
+				Description: "HIDORA_TOKEN or access_token of the credentials file when empty",
+			},
+			"token_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "File containing the access token, HIDORA_TOKEN_FILE when empty",
+			},
+			"credentials_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "INI file of credentials profiles, HIDORA_CREDENTIALS_FILE or ~/.hidora/credentials when empty",
+			},
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Profile of the credentials file, HIDORA_PROFILE or default when empty",
+			},
+			"scheme": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      strings.TrimSuffix(API_PROTO, "://"),
+				ValidateFunc: validation.StringInSlice([]string{"http", "https"}, false),
+				Description:  "Scheme of the API",
+			},
+			"api_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     API_VERSION,
+				Description: "Base path of the API on host",
+			},
+			"appid": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     PLATFORM_APPID,
+				Description: "Application ID of the platform sent with every request",
+			},
+			"ca_bundle": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "PEM file of the certificate authorities trusted in addition to the system ones",
+			},
+			"insecure_skip_verify": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Don't verify the certificate of the API, for labs only",
+			},
+			"session_cache": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Keep the session of username between runs, encrypted with HIDORA_SESSION_CACHE_KEY or the password",
+			},
+			"session_cache_dir": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     SESSION_CACHE_DEFAULT_DIR,
+				Description: "Directory of the session cache",
+			},
+			"max_requests_per_second": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      10,
+				ValidateFunc: validation.FloatAtLeast(0),
+				Description:  "Maximum number of API requests by second, 0 for no limit",
+			},
+			"max_concurrent_requests": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of API requests in flight, 0 for no limit",
+			},
+			"ssh_gateway": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Host of the SSH gateway of the nodes, gate.<domain> when empty and host is app.<domain>",
+			},
+			"default_region": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("HIDORA_REGION", nil),
+				Description: "Region of the environments which don't set one",
+			},
+			"default_envgroups": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Group of the environments which don't set one",
+			},
+			"default_node_labels": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Labels of every node, overridden by the labels of the nodes blocks",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
 			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"hidora_create_env": resourceHidoraCreateEnvironment(),
+			"hidora_create_env":    resourceHidoraCreateEnvironment(),
+			"hidora_letsencrypt":   resourceHidoraLetsEncrypt(),
+			"hidora_jps_install":   resourceHidoraJpsInstall(),
+			"hidora_deployment":    resourceHidoraDeployment(),
+			"hidora_endpoint":      resourceHidoraEndpoint(),
+			"hidora_firewall_rule": resourceHidoraFirewallRule(),
+			"hidora_ssh_key":       resourceHidoraSshKey(),
+			"hidora_cron":          resourceHidoraCron(),
+			"hidora_node_file":     resourceHidoraNodeFile(),
+			"hidora_exec":          resourceHidoraExec(),
+			"hidora_node_restart":  resourceHidoraNodeRestart(),
+			"hidora_backup":        resourceHidoraBackup(),
+			"hidora_env_share":     resourceHidoraEnvShare(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"hidora_create_env": dataSourceHidoraCreateEnvironment(),
+			"hidora_create_env":  dataSourceHidoraCreateEnvironment(),
+			"hidora_ssh_keys":    dataSourceHidoraSshKeys(),
+			"hidora_backups":     dataSourceHidoraBackups(),
+			"hidora_node_types":  dataSourceHidoraNodeTypes(),
+			"hidora_account":     dataSourceHidoraAccount(),
+			"hidora_env_billing": dataSourceHidoraEnvBilling(),
 		},
 		ConfigureContextFunc: providerConfigure,
 	}
@@ -89,80 +257,242 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 		BaseUrl:    &url.URL{},
 		HTTPClient: client,
 		Token:      "",
+		AppId:      d.Get("appid").(string),
 	}
 
-	var req_config JelasticRequest = JelasticRequest{
-		Method:  http.MethodPost,
-		Headers: client_headers,
-	}
-
-	username := d.Get("username").(string)
-	password := d.Get("password").(string)
-	access_token := d.Get("access_token").(string)
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
 
-	var host *string
+	transport, err := newTlsTransport(d.Get("ca_bundle").(string), d.Get("insecure_skip_verify").(bool))
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to read ca_bundle",
+			Detail:   err.Error(),
+		})
+		return nil, diags
+	}
+	client.Transport = newRateLimitedTransport(transport, d.Get("max_requests_per_second").(float64), d.Get("max_concurrent_requests").(int))
 
-	hVal, ok := d.GetOk("host")
-	if ok {
-		tempHost := hVal.(string)
-		host = &tempHost
+	c.DefaultRegion = d.Get("default_region").(string)
+	c.DefaultEnvgroups = d.Get("default_envgroups").(string)
+	c.DefaultNodeLabels = make(map[string]string)
+	for key, value := range d.Get("default_node_labels").(map[string]interface{}) {
+		c.DefaultNodeLabels[key] = value.(string)
 	}
 
-	// Warning or errors can be collected in a slice type
-	var diags diag.Diagnostics
+	credentials, credentials_diags := resolveCredentials(d)
+	diags = append(diags, credentials_diags...)
+	if diags.HasError() {
+		return nil, diags
+	}
+	log.Printf("[INFO] Using credentials from %s", credentials.Source)
 
-	u, _ := url.ParseRequestURI(API_PROTO + *host + API_VERSION)
+	// api_path always starts and ends with a slash, endpoints are appended
+	api_path := "/"
+	if trimmed_api_path := strings.Trim(d.Get("api_path").(string), "/"); trimmed_api_path != "" {
+		api_path += trimmed_api_path + "/"
+	}
+	u, err := url.ParseRequestURI(d.Get("scheme").(string) + "://" + credentials.Host + api_path)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid host",
+			Detail:   err.Error(),
+		})
+		return nil, diags
+	}
 	c.BaseUrl = u
 
-	if (username != "") && (password != "") {
-		u.Path = API_USERS_AUTH_SIGNIN_ENDPOINT
-		urlStr := u.String()
+	// The SSH gateway of the platforms is on gate.<domain> whereas the API is
+	// on app.<domain>, other hosts can't be guessed
+	c.SshGateway = d.Get("ssh_gateway").(string)
+	if c.SshGateway == "" && strings.HasPrefix(u.Hostname(), "app.") {
+		c.SshGateway = "gate." + strings.TrimPrefix(u.Hostname(), "app.")
+	}
 
-		req_config.Query = url.Values{
-			"appid":    {PLATFORM_APPID},
-			"login":    {username},
-			"password": {password},
+	if credentials.AccessToken == "" {
+		var session_cache *SessionCache
+		if d.Get("session_cache").(bool) {
+			session_cache = newSessionCache(d.Get("session_cache_dir").(string), u.Host, credentials.Username, credentials.Password)
+		}
+		// Runs before any request, then under session_lock from renewSession
+		c.refreshSession = func() error {
+			session, err := signIn(&c, credentials.Username, credentials.Password)
+			if err != nil {
+				return err
+			}
+			c.Token = session
+			session_cache.store(session)
+			return nil
 		}
 
-		req_config.Body = strings.NewReader(req_config.Query.Encode())
-		req, _ := http.NewRequest(req_config.Method, urlStr, req_config.Body)
-		req.Header = req_config.Headers
-		resp, err := client.Do(req)
-		if err != nil {
+		if session := session_cache.load(); session != "" && isSessionValid(&c, session) {
+			log.Printf("[INFO] Reusing cached session of %s", credentials.Username)
+			c.Token = session
+		} else if err := c.refreshSession(); err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
 				Summary:  "Unable to create Access token",
-				Detail:   "Unable to authenticate user for authenticated Hidora client",
+				Detail:   fmt.Sprintf("Sign in of %s with credentials from %s failed: %s", credentials.Username, credentials.Source, err),
 			})
 			return nil, diags
 		}
-		defer resp.Body.Close()
-		body, _ := ioutil.ReadAll(resp.Body)
-		var result map[string]interface{}
-		json.Unmarshal(body, &result)
-		c.Token = result["session"].(string)
+		discoverPlatform(&c)
 		return &c, diags
 	}
-	if access_token != "" {
-		is_string_alphabetic := regexp.MustCompile(`^[a-z0-9]*$`).MatchString
-		token_isalphanumeric := is_string_alphabetic(access_token)
-		token_length := len([]rune(access_token))
-		if token_isalphanumeric && (token_length <= TOKEN_LENGTH) {
-			c.Token = access_token
-			return &c, diags
-		} else {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "Incorrect token format",
-				Detail:   fmt.Sprintf("Token doesn't correspond to format policy ! Must have only alphanumeric with %d characters", TOKEN_LENGTH),
-			})
-			return nil, diags
+
+	is_string_alphabetic := regexp.MustCompile(`^[a-z0-9]*$`).MatchString
+	token_isalphanumeric := is_string_alphabetic(credentials.AccessToken)
+	token_length := len([]rune(credentials.AccessToken))
+	if token_isalphanumeric && (token_length <= TOKEN_LENGTH) {
+		c.Token = credentials.AccessToken
+		discoverPlatform(&c)
+		if c.supportsFeature(FEATURE_TOKEN_SCOPES) {
+			diags = append(diags, introspectToken(&c)...)
+			if diags.HasError() {
+				return nil, diags
+			}
 		}
+		return &c, diags
 	}
 	diags = append(diags, diag.Diagnostic{
 		Severity: diag.Error,
-		Summary:  "Unable to create or to get Access token",
-		Detail:   "Field username or password are empty and access_token is empty !",
+		Summary:  "Incorrect token format",
+		Detail:   fmt.Sprintf("Token from %s doesn't correspond to format policy ! Must have only alphanumeric with %d characters", credentials.Source, TOKEN_LENGTH),
 	})
 	return nil, diags
 }
+
+// Sign in with a username and a password and return the session
+func signIn(c *Client, username string, password string) (string, error) {
+	// Keep BaseUrl untouched for the next requests
+	u_signin := *c.BaseUrl
+	u_signin.Path += API_USERS_AUTH_SIGNIN_ENDPOINT
+	urlStr := u_signin.String()
+
+	var req_config JelasticRequest = JelasticRequest{
+		Method:  http.MethodPost,
+		Headers: client_headers,
+	}
+	req_config.Query = url.Values{
+		"appid":    {c.AppId},
+		"login":    {username},
+		"password": {password},
+	}
+
+	req_config.Body = strings.NewReader(req_config.Query.Encode())
+	req, _ := http.NewRequest(req_config.Method, urlStr, req_config.Body)
+	req.Header = req_config.Headers
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	var result map[string]interface{}
+	json.Unmarshal(body, &result)
+	session, ok := result["session"].(string)
+	if !ok {
+		return "", fmt.Errorf("%v", result["error"])
+	}
+	return session, nil
+}
+
+// Probe an API endpoint with the given parameters and return the decoded
+// response. appid and session are added when they are not already set. A
+// session expired during the run is renewed once when the provider signed in.
+func (c *Client) doJelasticRequest(endpoint string, query url.Values) (map[string]interface{}, error) {
+	// Fail before the request when the access token can't call the method
+	if err := c.checkEndpointsAllowed(endpoint); err != nil {
+		return nil, err
+	}
+
+	if query.Get("appid") == "" {
+		query.Set("appid", c.AppId)
+	}
+	session := query.Get("session")
+	if session == "" {
+		session = c.session()
+		query.Set("session", session)
+	}
+
+	result, err := c.sendJelasticRequest(endpoint, query)
+	if jelastic_err, ok := err.(*JelasticError); ok && jelastic_err.Result == API_RESULT_SESSION_EXPIRED && c.refreshSession != nil {
+		if err := c.renewSession(session); err != nil {
+			return result, err
+		}
+		query.Set("session", c.session())
+		return c.sendJelasticRequest(endpoint, query)
+	}
+	return result, err
+}
+
+// Current session, renewSession replaces it while other requests run
+func (c *Client) session() string {
+	c.session_lock.Lock()
+	defer c.session_lock.Unlock()
+	return c.Token
+}
+
+// Sign in again unless another request already replaced the expired session
+func (c *Client) renewSession(expired_session string) error {
+	c.session_lock.Lock()
+	defer c.session_lock.Unlock()
+
+	if c.Token != expired_session {
+		return nil
+	}
+	log.Printf("[INFO] Session expired, signing in again")
+	return c.refreshSession()
+}
+
+// Send a request to an API endpoint and decode the response
+func (c *Client) sendJelasticRequest(endpoint string, query url.Values) (map[string]interface{}, error) {
+	// Define REST URL
+	u := *c.BaseUrl
+	u.Path += endpoint
+	urlStr := u.String()
+
+	var req_config JelasticRequest = JelasticRequest{
+		Method:  http.MethodPost,
+		Headers: client_headers,
+		Query:   query,
+	}
+	req_config.Body = strings.NewReader(req_config.Query.Encode())
+	req, err := http.NewRequest(req_config.Method, urlStr, req_config.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = req_config.Headers
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("%s returned an invalid response: %s", endpoint, err)
+	}
+	// Some methods wrap their result into a response object
+	if _, ok := result["result"]; !ok {
+		if result_response, ok := result["response"].(map[string]interface{}); ok {
+			result = result_response
+		}
+	}
+	result_number, _ := result["result"].(float64)
+	if result_number != 0 {
+		return result, &JelasticError{
+			Endpoint: endpoint,
+			Result:   int(result_number),
+			Message:  fmt.Sprint(result["error"]),
+		}
+	}
+	return result, nil
+}
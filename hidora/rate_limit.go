@@ -0,0 +1,199 @@
+package hidora
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	RATE_LIMIT_MAX_RETRIES int           = 5
+	RATE_LIMIT_MIN_BACKOFF time.Duration = 1 * time.Second
+	RATE_LIMIT_MAX_BACKOFF time.Duration = 30 * time.Second
+)
+
+// HTTP statuses of throttled requests, also checked in the result code of
+// responses answered with 200. The Jelastic API documents no result code of
+// its own for throttling.
+var throttled_statuses = map[int]bool{
+	http.StatusTooManyRequests: true,
+}
+
+// HTTP transport which limits the number of requests by second with a
+// token bucket and the number of requests in flight with a semaphore.
+// Throttled requests are sent again after Retry-After or a backoff.
+type RateLimitedTransport struct {
+	next      http.RoundTripper
+	semaphore chan struct{}
+
+	// Token bucket, disabled when rate is 0
+	lock   sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// A limit of 0 disables the rate limit or the concurrency limit
+func newRateLimitedTransport(next http.RoundTripper, max_requests_per_second float64, max_concurrent_requests int) *RateLimitedTransport {
+	t := &RateLimitedTransport{
+		next:  next,
+		rate:  max_requests_per_second,
+		burst: math.Max(1, math.Ceil(max_requests_per_second)),
+		last:  time.Now(),
+	}
+	t.tokens = t.burst
+	if max_concurrent_requests > 0 {
+		t.semaphore = make(chan struct{}, max_concurrent_requests)
+	}
+	return t
+}
+
+// Wait for a token of the bucket
+func (t *RateLimitedTransport) wait(ctx context.Context) error {
+	if t.rate <= 0 {
+		return nil
+	}
+	for {
+		t.lock.Lock()
+		now := time.Now()
+		t.tokens = math.Min(t.burst, t.tokens+now.Sub(t.last).Seconds()*t.rate)
+		t.last = now
+		if t.tokens >= 1 {
+			t.tokens--
+			t.lock.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - t.tokens) / t.rate * float64(time.Second))
+		t.lock.Unlock()
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+func (t *RateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	if t.semaphore != nil {
+		select {
+		case t.semaphore <- struct{}{}:
+			defer func() { <-t.semaphore }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := t.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		attempt_req := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attempt_req = req.Clone(ctx)
+			attempt_req.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(attempt_req)
+		if err != nil {
+			return resp, err
+		}
+		throttled, err := isThrottled(resp)
+		if err != nil {
+			return nil, err
+		}
+		// The request can only be sent again when its body can be rebuilt
+		if !throttled || attempt >= RATE_LIMIT_MAX_RETRIES || (req.Body != nil && req.GetBody == nil) {
+			return resp, nil
+		}
+
+		delay := retryAfter(resp, attempt)
+		resp.Body.Close()
+		log.Printf("[WARN] %s throttled, retrying in %s", req.URL.Path, delay)
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Whether the request was throttled, with the HTTP status or with the same
+// status given as result code. The body of the response is kept readable. 503
+// isn't throttling, the API methods aren't idempotent and the request may have
+// been processed.
+func isThrottled(resp *http.Response) (bool, error) {
+	if throttled_statuses[resp.StatusCode] {
+		return true, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return false, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var result struct {
+		Result   *float64 `json:"result"`
+		Response struct {
+			Result *float64 `json:"result"`
+		} `json:"response"`
+	}
+	if json.Unmarshal(body, &result) != nil {
+		return false, nil
+	}
+	if result.Result == nil {
+		result.Result = result.Response.Result
+	}
+	return result.Result != nil && throttled_statuses[int(*result.Result)], nil
+}
+
+// Delay before sending a throttled request again, from Retry-After (seconds
+// or HTTP date) or an exponential backoff
+func retryAfter(resp *http.Response, attempt int) time.Duration {
+	if retry_after := resp.Header.Get("Retry-After"); retry_after != "" {
+		if seconds, err := strconv.Atoi(retry_after); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if date, err := http.ParseTime(retry_after); err == nil {
+			if delay := time.Until(date); delay > 0 {
+				return delay
+			}
+			return 0
+		}
+	}
+	delay := RATE_LIMIT_MIN_BACKOFF << uint(attempt)
+	if delay > RATE_LIMIT_MAX_BACKOFF {
+		delay = RATE_LIMIT_MAX_BACKOFF
+	}
+	return delay
+}
+
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,181 @@
+package hidora
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newRateLimitedTestClient(max_requests_per_second float64, max_concurrent_requests int) *http.Client {
+	return &http.Client{
+		Transport: newRateLimitedTransport(http.DefaultTransport, max_requests_per_second, max_concurrent_requests),
+	}
+}
+
+func postForm(t *testing.T, client *http.Client, server_url string) *http.Response {
+	resp, err := client.PostForm(server_url, url.Values{"envName": {"env"}})
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+	return resp
+}
+
+func TestRateLimitedTransportConcurrency(t *testing.T) {
+	var in_flight, max_in_flight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&in_flight, 1)
+		defer atomic.AddInt32(&in_flight, -1)
+		for {
+			max := atomic.LoadInt32(&max_in_flight)
+			if current <= max || atomic.CompareAndSwapInt32(&max_in_flight, max, current) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprint(w, `{"result":0}`)
+	}))
+	defer server.Close()
+
+	client := newRateLimitedTestClient(0, 3)
+	var wg sync.WaitGroup
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// t.Fatalf can't be called outside of the test goroutine
+			resp, err := client.PostForm(server.URL, url.Values{"envName": {"env"}})
+			if err != nil {
+				t.Errorf("request failed: %s", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if max_in_flight != 3 {
+		t.Fatalf("expected 3 requests in flight at most, got %d", max_in_flight)
+	}
+}
+
+func TestRateLimitedTransportRate(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprint(w, `{"result":0}`)
+	}))
+	defer server.Close()
+
+	// A burst of 10 requests then 10 by second
+	client := newRateLimitedTestClient(10, 0)
+	start := time.Now()
+	for i := 0; i < 15; i++ {
+		postForm(t, client, server.URL).Body.Close()
+	}
+	elapsed := time.Since(start)
+
+	if requests != 15 {
+		t.Fatalf("expected 15 requests, got %d", requests)
+	}
+	if elapsed < 400*time.Millisecond {
+		t.Fatalf("15 requests at 10 by second took only %s", elapsed)
+	}
+}
+
+func TestRateLimitedTransportRetryAfter(t *testing.T) {
+	var requests int32
+	var bodies []string
+	var lock sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		lock.Lock()
+		bodies = append(bodies, string(body))
+		lock.Unlock()
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"result":0}`)
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	resp := postForm(t, newRateLimitedTestClient(0, 0), server.URL)
+	resp.Body.Close()
+	elapsed := time.Since(start)
+
+	if resp.StatusCode != http.StatusOK || requests != 2 {
+		t.Fatalf("expected a retry answered with 200, got %d after %d requests", resp.StatusCode, requests)
+	}
+	if elapsed < time.Second {
+		t.Fatalf("Retry-After of 1 second not honoured, retried after %s", elapsed)
+	}
+	if bodies[0] != "envName=env" || bodies[1] != bodies[0] {
+		t.Fatalf("body not sent again, got %q", bodies)
+	}
+}
+
+// A throttled request may only be reported in the result code of a response
+// answered with 200, e.g. by a proxy in front of the API
+func TestRateLimitedTransportThrottledResult(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			fmt.Fprint(w, `{"response":{"result":429,"error":"too many requests"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"result":0,"env":"ok"}`)
+	}))
+	defer server.Close()
+
+	resp := postForm(t, newRateLimitedTestClient(0, 0), server.URL)
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	if requests != 2 {
+		t.Fatalf("expected a retry of the throttled result, got %d requests", requests)
+	}
+	if !strings.Contains(string(body), `"env":"ok"`) {
+		t.Fatalf("expected the body of the retry, got %s", body)
+	}
+}
+
+func TestRateLimitedTransportNoRetry(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"service unavailable", http.StatusServiceUnavailable, "unavailable"},
+		{"other result", http.StatusOK, `{"result":702,"error":"session expired"}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				w.WriteHeader(c.status)
+				fmt.Fprint(w, c.body)
+			}))
+			defer server.Close()
+
+			resp := postForm(t, newRateLimitedTestClient(0, 0), server.URL)
+			defer resp.Body.Close()
+			body, _ := ioutil.ReadAll(resp.Body)
+
+			if requests != 1 || resp.StatusCode != c.status || string(body) != c.body {
+				t.Fatalf("expected one request answered with %d %s, got %d requests answered with %d %s",
+					c.status, c.body, requests, resp.StatusCode, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package hidora
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	BACKUP_ADDON_MANIFEST_URL      string = "https://raw.githubusercontent.com/jelastic-jps/backup-addon/master/manifest.yml"
+	BACKUP_CONFIGURE_ACTION        string = "configure"
+	BACKUP_SCHEDULE_TYPE_CRON      string = "3" // Custom crontab
+	BACKUP_SETTINGS_SCHEDULE_TYPE  string = "scheduleType"
+	BACKUP_SETTINGS_CRON_TIME      string = "cronTime"
+	BACKUP_SETTINGS_BACKUP_COUNT   string = "backupCount"
+	BACKUP_SETTINGS_STORAGE_NAME   string = "storageName"
+	BACKUP_STORAGE_NODEGROUP       string = "storage"
+	BACKUP_STORAGE_REPOSITORY_ROOT string = "/data/"
+)
+
+func resourceHidoraBackup() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceJelasticBackupCreate,
+		ReadContext:   resourceJelasticBackupRead,
+		UpdateContext: resourceJelasticBackupUpdate,
+		DeleteContext: resourceJelasticBackupDelete,
+		CustomizeDiff: checkResourceEndpoints(
+			API_MARKETPLACE_JPS_INSTALL_ENDPOINT,
+			API_MARKETPLACE_APP_GETADDONLIST_ENDPOINT,
+			API_MARKETPLACE_JPS_EXECUTEAPPACTION_ENDPOINT,
+			API_MARKETPLACE_JPS_UNINSTALL_ENDPOINT,
+		),
+		SchemaVersion: 0,
+		Schema: map[string]*schema.Schema{
+			"env_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the environment to back up",
+			},
+			"nodegroup": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Stateful node group to back up",
+			},
+			"storage_env": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the storage environment receiving the backups",
+			},
+			"schedule": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateFunc:     validateCronSchedule,
+				DiffSuppressFunc: suppressCronScheduleDiff,
+				Description:      "Cron expression of the backups",
+			},
+			"retention": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      5,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Number of backups kept in the storage",
+			},
+			"manifest_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     BACKUP_ADDON_MANIFEST_URL,
+				ForceNew:    true,
+				Description: "Manifest of the backup add-on",
+			},
+		},
+	}
+}
+
+func resourceJelasticBackupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	settings_json, _ := json.Marshal(expandBackupSettings(d))
+
+	result, err := m.doJelasticRequest(API_MARKETPLACE_JPS_INSTALL_ENDPOINT, url.Values{
+		"jps":       {d.Get("manifest_url").(string)},
+		"envName":   {d.Get("env_name").(string)},
+		"nodeGroup": {d.Get("nodegroup").(string)},
+		"settings":  {string(settings_json)},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to install backup add-on",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	unique_name, ok := result["uniqueName"].(string)
+	if !ok {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to install backup add-on",
+			Detail:   "API response doesn't contain the unique name of the add-on",
+		})
+		return diags
+	}
+	d.SetId(unique_name)
+
+	return resourceJelasticBackupRead(ctx, d, meta)
+}
+
+func resourceJelasticBackupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	result, err := m.doJelasticRequest(API_MARKETPLACE_APP_GETADDONLIST_ENDPOINT, url.Values{
+		"envName":   {d.Get("env_name").(string)},
+		"nodeGroup": {d.Get("nodegroup").(string)},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get add-ons informations",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	addon := findInstalledAddon(result, d.Id())
+	if addon == nil {
+		// Add-on has been uninstalled outside of Terraform
+		d.SetId("")
+		return diags
+	}
+	settings := flattenAddonSettings(addon)
+
+	if storage_name, ok := settings[BACKUP_SETTINGS_STORAGE_NAME].(string); ok {
+		_ = d.Set("storage_env", storage_name)
+	}
+	if cron_time, ok := settings[BACKUP_SETTINGS_CRON_TIME].(string); ok {
+		_ = d.Set("schedule", cron_time)
+	}
+	// backupCount is a string or a number depending on the add-on version
+	switch backup_count := settings[BACKUP_SETTINGS_BACKUP_COUNT].(type) {
+	case float64:
+		_ = d.Set("retention", int(backup_count))
+	case string:
+		if retention, err := strconv.Atoi(backup_count); err == nil {
+			_ = d.Set("retention", retention)
+		}
+	}
+
+	return diags
+}
+
+func resourceJelasticBackupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	settings_json, _ := json.Marshal(expandBackupSettings(d))
+
+	_, err := m.doJelasticRequest(API_MARKETPLACE_JPS_EXECUTEAPPACTION_ENDPOINT, url.Values{
+		"appUniqueName": {d.Id()},
+		"action":        {BACKUP_CONFIGURE_ACTION},
+		"settings":      {string(settings_json)},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to update backup add-on",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	return resourceJelasticBackupRead(ctx, d, meta)
+}
+
+func resourceJelasticBackupDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	_, err := m.doJelasticRequest(API_MARKETPLACE_JPS_UNINSTALL_ENDPOINT, url.Values{
+		"appUniqueName": {d.Id()},
+		"force":         {"true"},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Unable to uninstall backup add-on %s", d.Id()),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	return diags
+}
+
+func expandBackupSettings(d *schema.ResourceData) map[string]interface{} {
+	return map[string]interface{}{
+		BACKUP_SETTINGS_SCHEDULE_TYPE: BACKUP_SCHEDULE_TYPE_CRON,
+		BACKUP_SETTINGS_CRON_TIME:     d.Get("schedule").(string),
+		BACKUP_SETTINGS_BACKUP_COUNT:  strconv.Itoa(d.Get("retention").(int)),
+		BACKUP_SETTINGS_STORAGE_NAME:  d.Get("storage_env").(string),
+		"nodeGroup":                   d.Get("nodegroup").(string),
+	}
+}
@@ -4,12 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"log"
+	"net"
 	"net/url"
+	"reflect"
 	"regexp"
 	"strconv"
-	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -62,7 +62,6 @@ type Createenvironment struct {
 	Environment *Envsettings
 	Nodes       []*Nodes
 	Owneruid    uint32
-	Session     string
 }
 
 type Region struct {
@@ -78,17 +77,33 @@ const (
 	API_ENV_CONTROL_CHANGETOPOLOGY_ENDPOINT string = "environment/control/rest/changetopology"
 	API_ENV_CONTROL_SETENVGROUP_ENDPOINT    string = "environment/control/rest/setenvgroup"
 	API_ENV_CONTROL_MIGRATE_ENDPOINT        string = "environment/control/rest/migrate"
+	API_ENV_CONTROL_ATTACHEXTIP_ENDPOINT    string = "environment/control/rest/attachextip"
+	API_ENV_CONTROL_DETACHEXTIP_ENDPOINT    string = "environment/control/rest/detachextip"
+	SSH_GATEWAY_PORT                        int    = 3022
 	APPID_LENGTH                            int    = 32
 	SHORTDOMAIN_MIN_LENGTH                  int    = 5  // Not be so sure
 	SHORTDOMAIN_MAX_LENGTH                  int    = 41 // Not be so sure
 )
 
+// Plan-time checks and estimation
+const (
+	API_ENV_NODEGROUP_APPLYDATA_ENDPOINT    string  = "environment/nodegroup/rest/applydata"
+	API_BILLING_PRICING_GETPRICING_ENDPOINT string  = "billing/pricing/rest/getpricing"
+	PRICING_RESOURCE_FIXED_CLOUDLET         string  = "FIXED_CLOUDLET"
+	PRICING_RESOURCE_FLEXIBLE_CLOUDLET      string  = "FLEXIBLE_CLOUDLET"
+	PRICING_RESOURCE_DISK                   string  = "DISK"
+	PRICING_RESOURCE_IP                     string  = "IP"
+	HOURS_BY_MONTH                          float64 = 730
+	QUOTA_WARNING_RATIO                     float64 = 0.8
+)
+
 func resourceHidoraCreateEnvironment() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceJelasticCreateEnvironmentCreate,
 		ReadContext:   resourceJelasticCreateEnvironmentRead,
 		UpdateContext: resourceJelasticCreateEnvironmentUpdate,
 		DeleteContext: resourceJelasticCreateEnvironmentDelete,
+		CustomizeDiff: resourceJelasticCreateEnvironmentCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -97,8 +112,8 @@ func resourceHidoraCreateEnvironment() *schema.Resource {
 			"appid": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Default:     PLATFORM_APPID,
-				Description: "Application Identity in Jelastic Platform",
+				Computed:    true,
+				Description: "Application Identity in Jelastic Platform, appid of the provider when empty",
 			},
 			"environment": {
 				Type:        schema.TypeList,
@@ -115,7 +130,7 @@ func resourceHidoraCreateEnvironment() *schema.Resource {
 						"region": {
 							Type:        schema.TypeString,
 							Optional:    true,
-							Description: "",
+							Description: "Region of the environment, default_region of the provider when empty",
 						},
 						"shortdomain": { // Verify policy
 							Type:        schema.TypeString,
@@ -198,6 +213,29 @@ func resourceHidoraCreateEnvironment() *schema.Resource {
 							Default:     false,
 							Description: "",
 						},
+						"extip_count": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: validation.IntAtLeast(0),
+							Description:  "Number of public IPv4 per node, extip attaches one when 0",
+						},
+						"extip_addresses": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "Public IPv4 addresses of the nodes",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"extipv6_addresses": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "Public IPv6 addresses of the nodes",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
 						"fixedcloudlets": {
 							Type:        schema.TypeInt,
 							Optional:    true,
@@ -215,6 +253,14 @@ func resourceHidoraCreateEnvironment() *schema.Resource {
 							Optional:    true,
 							Description: "",
 						},
+						"labels": {
+							Type:        schema.TypeMap,
+							Optional:    true,
+							Description: "Labels of the nodes, merged with default_node_labels of the provider",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
 						"mission": {
 							Type:        schema.TypeString,
 							Optional:    true,
@@ -228,7 +274,7 @@ func resourceHidoraCreateEnvironment() *schema.Resource {
 						"nodetype": {
 							Type:        schema.TypeString,
 							Required:    true,
-							Description: "",
+							Description: "Stack of the node group, one of hidora_node_types",
 						},
 						"restartdelay": {
 							Type:        schema.TypeInt,
@@ -287,7 +333,116 @@ func resourceHidoraCreateEnvironment() *schema.Resource {
 			"envgroups": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "Define which group is chosen for the environment",
+				Description: "Define which group is chosen for the environment, default_envgroups of the provider when empty",
+			},
+			"envgroups_all": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Group of the environment, from envgroups or default_envgroups of the provider",
+			},
+			"region": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Region of the environment, from environment.region or default_region of the provider",
+			},
+			"node_labels_all": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Labels of every nodes block, default_node_labels of the provider included",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"nodegroup": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"labels": {
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Description: "",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+			"estimated_hourly_cost": {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "Estimated cost by hour of the nodes, with flexible cloudlets fully used",
+			},
+			"estimated_monthly_cost": {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "Estimated cost by month of the nodes, with flexible cloudlets fully used",
+			},
+			"quota_warnings": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Quotas of the account nearly reached by the nodes, exceeded quotas fail the plan. terraform plan doesn't print them, check them in an output or a postcondition",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"node_instances": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Nodes created by the platform for every nodes block",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "",
+						},
+						"nodegroup": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"intip": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Internal IP address of the node",
+						},
+						"url": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"adminurl": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"status": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Status code of the node, 1 when running",
+						},
+						"ismaster": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "",
+						},
+						"ssh_user": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "User to connect to the node through the SSH gateway",
+						},
+						"ssh_host": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Host of the SSH gateway, empty when the provider can't guess it (see ssh_gateway)",
+						},
+						"ssh_port": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Port of the SSH gateway",
+						},
+					},
+				},
 			},
 		},
 	}
@@ -296,17 +451,9 @@ func resourceHidoraCreateEnvironment() *schema.Resource {
 func resourceJelasticCreateEnvironmentCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	// Statement of m and type assertion with *CLient
 	m := meta.(*Client)
-	// Statement of http client to do API requests
-	client := m.HTTPClient
-
 	// Declare diag variable for debugging
 	var diags diag.Diagnostics
 
-	// Define REST URL
-	u := *m.BaseUrl
-	u.Path += API_ENV_CONTROL_CREATEENV_ENDPOINT
-	urlStr := u.String()
-
 	// Allocation of CreateEnvironment struct
 	createenv := new(Createenvironment)
 	createenv.Environment = new(Envsettings)
@@ -323,6 +470,10 @@ func resourceJelasticCreateEnvironmentCreate(ctx context.Context, d *schema.Reso
 	// Fill Struct Createenvironment
 	// Check appid format
 	appid := d.Get("appid").(string)
+	if appid == "" {
+		appid = m.AppId
+		_ = d.Set("appid", appid)
+	}
 	is_string_alphabetic := regexp.MustCompile(`^[a-z0-9]*$`).MatchString
 	appid_isalphanumeric := is_string_alphabetic(appid)
 	appid_length := len([]rune(appid))
@@ -339,9 +490,6 @@ func resourceJelasticCreateEnvironmentCreate(ctx context.Context, d *schema.Reso
 		return diags
 	}
 
-	// Check session format
-	createenv.Session = m.Token
-
 	// Check actionkey
 	createenv.Actionkey = d.Get("actionkey").(string)
 
@@ -350,7 +498,7 @@ func resourceJelasticCreateEnvironmentCreate(ctx context.Context, d *schema.Reso
 
 	// Check envgroups
 	// API method can create a new envgroup if it doesn't exist
-	createenv.Envgroups = d.Get("envgroups").(string)
+	createenv.Envgroups = d.Get("envgroups_all").(string)
 
 	// Get all values of environment
 	tf_env := d.Get("environment").([]interface{})[0]
@@ -371,74 +519,45 @@ func resourceJelasticCreateEnvironmentCreate(ctx context.Context, d *schema.Reso
 	}
 
 	// Check region
-	// Define REST URL
-	u_getregions := *m.BaseUrl
-	u_getregions.Path += API_ENV_CONTROL_GETREGIONS_ENDPOINT
-	urlStr_getregions := u_getregions.String()
-
-	// Define request configuration
-	var req_region JelasticRequest = JelasticRequest{
-		Method:  http.MethodPost,
-		Headers: client_headers,
-	}
-
-	req_region.Query = url.Values{
-		"appid":   {PLATFORM_APPID},
-		"session": {m.Token},
-	}
-	req_region.Body = strings.NewReader(req_region.Query.Encode())
-	req, _ := http.NewRequest(req_region.Method, urlStr_getregions, req_region.Body)
-	req.Header = req_region.Headers
-	resp_getregions, err := client.Do(req)
+	result_getregions, err := m.doJelasticRequest(API_ENV_CONTROL_GETREGIONS_ENDPOINT, url.Values{
+		"appid": {createenv.Appid},
+	})
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Unable to get regions",
-			Detail:   "Wrong appid or session token",
-		})
-		return diags
-	}
-	defer resp_getregions.Body.Close()
-	body, _ := ioutil.ReadAll(resp_getregions.Body)
-	var result_getregions map[string]interface{}
-	json.Unmarshal(body, &result_getregions)
-	if result_getregions["result"].(float64) != 0 {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "Unable to get regions",
-			Detail:   "Wrong appid or session token",
+			Detail:   err.Error(),
 		})
 		return diags
 	}
-	result_array := result_getregions["array"].([]interface{})
-	result_array_len := len(result_array)
-	regions := make([]Region, result_array_len)
+	result_array, _ := result_getregions["array"].([]interface{})
 	is_region_accepted := false
 	detail_region_message := ""
-	for i := 0; i < result_array_len; i++ {
-		result_array_id := result_array[i].(map[string]interface{})
-		hardnodes_array := result_array_id["hardNodeGroups"].([]interface{})
-		hardnodes_array_len := len(hardnodes_array)
-		for j := 0; j < hardnodes_array_len; j++ {
-			hardnodes_array_id := hardnodes_array[j].(map[string]interface{})
-			if hardnodes_array_id["isEnabled"] == true {
-				regions[i].Uniquename = hardnodes_array_id["uniqueName"].(string)
-				regions[i].Displayname = hardnodes_array_id["displayName"].(string)
-				detail_region_message += fmt.Sprintf("Region: %s, value: %s ",
-					regions[i].Displayname,
-					regions[i].Uniquename)
-				if tf_env_data["region"].(string) == regions[i].Uniquename {
-					is_region_accepted = true
-					break
-				}
+	for _, result_array_id := range result_array {
+		result_array_map, _ := result_array_id.(map[string]interface{})
+		hardnodes_array, _ := result_array_map["hardNodeGroups"].([]interface{})
+		for _, hardnodes_array_id := range hardnodes_array {
+			hardnodes_array_map, _ := hardnodes_array_id.(map[string]interface{})
+			if hardnodes_array_map["isEnabled"] != true {
+				continue
+			}
+			var region Region
+			region.Uniquename, _ = hardnodes_array_map["uniqueName"].(string)
+			region.Displayname, _ = hardnodes_array_map["displayName"].(string)
+			detail_region_message += fmt.Sprintf("Region: %s, value: %s ",
+				region.Displayname,
+				region.Uniquename)
+			if d.Get("region").(string) == region.Uniquename {
+				is_region_accepted = true
+				break
 			}
 		}
 		if is_region_accepted {
 			break
 		}
 	}
-	region, ok := tf_env_data["region"].(string)
-	if ok && is_region_accepted {
+	region := d.Get("region").(string)
+	if is_region_accepted {
 		(*env).Region = region
 	} else {
 		diags = append(diags, diag.Diagnostic{
@@ -511,7 +630,7 @@ func resourceJelasticCreateEnvironmentCreate(ctx context.Context, d *schema.Reso
 			node_env_map[k] = value
 		}
 		node.Env = node_env_map
-		node.Extip = tf_node["extip"].(bool)
+		node.Extip = tf_node["extip"].(bool) || tf_node["extip_count"].(int) > 0
 		node.Extipv6 = tf_node["extipv6"].(bool)
 		node.Fixedcloudlets = uint8(tf_node["fixedcloudlets"].(int))
 		node.Flexiblecloudlets = uint8(tf_node["flexiblecloudlets"].(int))
@@ -548,14 +667,8 @@ func resourceJelasticCreateEnvironmentCreate(ctx context.Context, d *schema.Reso
 	nodes_json_string := string(nodes_json)
 
 	// Probe API Server with parameters
-	var req_config JelasticRequest = JelasticRequest{
-		Method:  http.MethodPost,
-		Headers: client_headers,
-	}
-
-	req_config.Query = url.Values{
-		"appid":     {PLATFORM_APPID},
-		"session":   {m.Token},
+	query := url.Values{
+		"appid":     {createenv.Appid},
 		"env":       {env_json_string},                       // JSON env
 		"nodes":     {nodes_json_string},                     // JSON nodes
 		"actionkey": {createenv.Actionkey},                   // Optional
@@ -563,52 +676,65 @@ func resourceJelasticCreateEnvironmentCreate(ctx context.Context, d *schema.Reso
 		"envgroups": {createenv.Envgroups},                   // Optional
 	}
 	if createenv.Actionkey == "" {
-		req_config.Query.Del("actionkey")
+		query.Del("actionkey")
 	}
 	if createenv.Owneruid == 0 {
-		req_config.Query.Del("owneruid")
+		query.Del("owneruid")
 	}
 	if createenv.Envgroups == "" {
-		req_config.Query.Del("envgroups")
+		query.Del("envgroups")
 	}
 
-	req_config.Body = strings.NewReader(req_config.Query.Encode())
-	req, _ = http.NewRequest(req_config.Method, urlStr, req_config.Body)
-	req.Header = req_config.Headers
-
-	// Take Response
-	resp, err := client.Do(req)
+	result, err := m.doJelasticRequest(API_ENV_CONTROL_CREATEENV_ENDPOINT, query)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Unable to create environment",
-			Detail:   "Can't create environment because of timeout or bad values in paramaters",
+			Detail:   err.Error(),
 		})
 		return diags
 	}
-	defer resp.Body.Close()
-
-	body, _ = ioutil.ReadAll(resp.Body)
-	var result map[string]interface{}
-	json.Unmarshal(body, &result)
-	result_response := result["response"].(map[string]interface{})
-	result_number := result_response["result"].(float64)
-	if result_number == 2314 {
+	env_name, ok := result["name"].(string)
+	if !ok {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
-			Summary:  "API request failed",
-			Detail:   fmt.Sprint(result_response["error"]),
+			Summary:  "Unable to create environment",
+			Detail:   "API response doesn't contain the name of the created environment",
 		})
 		return diags
-	} else if result_number != 0 {
+	}
+	d.SetId(env_name) // Because API only search by shortdomain of environment
+
+	// createenvironment only attaches one public IPv4 per node
+	for _, tf_node := range tf_nodes {
+		tf_node_data := tf_node.(map[string]interface{})
+		if tf_node_data["extip_count"].(int) > 1 {
+			if err := syncNodeGroupExtIps(m, d.Id(), tf_node_data); err != nil {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  "Unable to attach public IPs",
+					Detail:   err.Error(),
+				})
+				return diags
+			}
+		}
+	}
+
+	// Node groups are created without labels
+	node_labels_all := []interface{}{}
+	for _, node_labels := range d.Get("node_labels_all").([]interface{}) {
+		if len(node_labels.(map[string]interface{})["labels"].(map[string]interface{})) > 0 {
+			node_labels_all = append(node_labels_all, node_labels)
+		}
+	}
+	if err := applyNodeLabels(m, d.Id(), node_labels_all); err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
-			Summary:  "API request failed",
-			Detail:   "Incorrect value in fields", // Not so much explicit response :/
+			Summary:  "Unable to set labels of nodes",
+			Detail:   err.Error(),
 		})
 		return diags
 	}
-	d.SetId(result_response["name"].(string)) // Because API only search by shortdomain of environment
 
 	return resourceJelasticCreateEnvironmentRead(ctx, d, meta)
 }
@@ -616,57 +742,39 @@ func resourceJelasticCreateEnvironmentCreate(ctx context.Context, d *schema.Reso
 func resourceJelasticCreateEnvironmentRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	// Statement of m and type assertion with *CLient
 	m := meta.(*Client)
-	// Statement of http client to do API requests
-	client := m.HTTPClient
-	session := m.Token
 
 	// Declare diag variable for debugging
 	var diags diag.Diagnostics
 
-	// Define REST URL
-	u := *m.BaseUrl
-	u.Path += API_ENV_CONTROL_GETENVINFO_ENDPOINT
-	urlStr := u.String()
-
-	var req_config JelasticRequest = JelasticRequest{
-		Method:  http.MethodPost,
-		Headers: client_headers,
-	}
-
-	req_config.Query = url.Values{
+	result, err := m.doJelasticRequest(API_ENV_CONTROL_GETENVINFO_ENDPOINT, url.Values{
 		"envName": {d.Id()},
-		"session": {session},
-		"lazy":    {"true"}, // To have less informations, can be changed
-	}
-	req_config.Body = strings.NewReader(req_config.Query.Encode())
-	req, _ := http.NewRequest(req_config.Method, urlStr, req_config.Body)
-	req.Header = req_config.Headers
-
-	resp, err := client.Do(req)
+		"lazy":    {"false"}, // Need nodes informations for public IPs
+	})
 	if err != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "Unable to get environment informations",
-			Detail:   "Can't get informations about environment because of bad envName",
-		})
-		return diags
-	}
-	defer resp.Body.Close()
-
-	body, _ := ioutil.ReadAll(resp.Body)
-	var result map[string]interface{}
-	json.Unmarshal(body, &result)
-	if result["result"].(float64) != 0 {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "API request to getenvinfo failed",
-			Detail:   fmt.Sprintf("Cannot get environment informations from %s", d.Id()),
+			Detail:   fmt.Sprintf("Cannot get environment informations from %s: %s", d.Id(), err),
 		})
 		return diags
 	}
 	result_response := result["env"].(map[string]interface{})
-	_ = d.Set("environment", flattenCreateEnvironmentEnvironmentData(result_response))
+	result_nodes, _ := result["nodes"].([]interface{})
+	result_envgroups, _ := result["envGroups"].([]interface{})
+	// Only the configured region is kept in the environment block, the
+	// region of the platform goes to region
+	environment := flattenCreateEnvironmentEnvironmentData(result_response).([]map[string]interface{})
+	environment[0]["region"] = d.Get("environment.0.region").(string)
+	_ = d.Set("environment", environment)
+	_ = d.Set("region", result_response["hostGroup"].(map[string]interface{})["uniqueName"])
+	envgroups_all := ""
+	if len(result_envgroups) > 0 {
+		envgroups_all, _ = result_envgroups[0].(string)
+	}
+	_ = d.Set("envgroups_all", envgroups_all)
 	_ = d.Set("owneruid", result_response["uid"].(float64))
+	_ = d.Set("nodes", setCreateEnvironmentNodesExtIps(d.Get("nodes").([]interface{}), result_nodes))
+	_ = d.Set("node_instances", flattenCreateEnvironmentNodeInstances(result_nodes, result_response["uid"].(float64), m.SshGateway))
 
 	return nil
 }
@@ -675,80 +783,73 @@ func resourceJelasticCreateEnvironmentRead(ctx context.Context, d *schema.Resour
 func resourceJelasticCreateEnvironmentUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	// Statement of m and type assertion with *CLient
 	m := meta.(*Client)
-	// Statement of http client to do API requests
-	client := m.HTTPClient
-	session := m.Token
 
 	// Declare diag variable for debugging
 	var diags diag.Diagnostics
 
-	// Define REST URL
-	u := *m.BaseUrl
-
-	var req_config JelasticRequest = JelasticRequest{
-		Method:  http.MethodPost,
-		Headers: client_headers,
-	}
-
-	// envgroups -> setenvgroups API method
+	// envgroups_all -> setenvgroups API method
 	// ishaenabled -> ChangeTopology API method
 	// region -> migrate API method (don"t forget to check hardwarenodegroup)
 	// shortdomain -> None recreate resource
 	// sslstate -> ChangeTopology API method
 
-	if d.HasChange("envgroups") {
-		u_envgroups := u
-		u_envgroups.Path += API_ENV_CONTROL_SETENVGROUP_ENDPOINT
-		urlStr := u_envgroups.String()
-
-		req_config.Query = url.Values{
+	if d.HasChange("envgroups_all") {
+		_, err := m.doJelasticRequest(API_ENV_CONTROL_SETENVGROUP_ENDPOINT, url.Values{
 			"envName":  {d.Id()},
-			"session":  {session},
-			"envGroup": {d.Get("envgroups").(string)},
-		}
-
-		req_config.Body = strings.NewReader(req_config.Query.Encode())
-		req, _ := http.NewRequest(req_config.Method, urlStr, req_config.Body)
-		req.Header = req_config.Headers
-
-		resp, err := client.Do(req)
+			"envGroup": {d.Get("envgroups_all").(string)},
+		})
 		if err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
 				Summary:  "Unable to set an environment groups",
-				Detail:   "",
+				Detail:   err.Error(),
 			})
 			return diags
 		}
-		defer resp.Body.Close()
 	}
-	if d.HasChange("environment.0.region") {
-		u_region := u
-		u_region.Path += API_ENV_CONTROL_MIGRATE_ENDPOINT
-		urlStr := u_region.String()
-
-		req_config.Query = url.Values{
+	if d.HasChange("region") {
+		_, err := m.doJelasticRequest(API_ENV_CONTROL_MIGRATE_ENDPOINT, url.Values{
 			"envName":           {d.Id()},
-			"session":           {session},
-			"hardwareNodeGroup": {d.Get("environment.0.region").(string)}, // No check /!\
-			"isOnline":          {"true"},                                 // arbitrary, can be modified
+			"hardwareNodeGroup": {d.Get("region").(string)}, // No check /!\
+			"isOnline":          {"true"},                   // arbitrary, can be modified
+		})
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary: fmt.Sprintf("Unable to migrate environment to %s",
+					d.Get("region").(string)),
+				Detail: err.Error(),
+			})
+			return diags
 		}
+	}
 
-		req_config.Body = strings.NewReader(req_config.Query.Encode())
-		req, _ := http.NewRequest(req_config.Method, urlStr, req_config.Body)
-		req.Header = req_config.Headers
+	// extip, extipv6, extip_count -> attachextip and detachextip API methods
+	tf_nodes := d.Get("nodes").([]interface{})
+	for i, tf_node := range tf_nodes {
+		if d.HasChange(fmt.Sprintf("nodes.%d.extip", i)) ||
+			d.HasChange(fmt.Sprintf("nodes.%d.extipv6", i)) ||
+			d.HasChange(fmt.Sprintf("nodes.%d.extip_count", i)) {
+			if err := syncNodeGroupExtIps(m, d.Id(), tf_node.(map[string]interface{})); err != nil {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  fmt.Sprintf("Unable to update public IPs of %s", tf_node.(map[string]interface{})["nodegroup"].(string)),
+					Detail:   err.Error(),
+				})
+				return diags
+			}
+		}
+	}
 
-		resp, err := client.Do(req)
-		if err != nil {
+	if d.HasChange("node_labels_all") {
+		if err := applyNodeLabels(m, d.Id(), d.Get("node_labels_all").([]interface{})); err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
-				Summary: fmt.Sprintf("Unable to migrate environment to %s",
-					d.Get("environment.0.region").(string)),
-				Detail: "Cannot find hardwareNodeGroup",
+				Summary:  "Unable to update labels of nodes",
+				Detail:   err.Error(),
 			})
 			return diags
 		}
-		defer resp.Body.Close()
 	}
 
 	// Reapeat the same checks as resourceJelasticCreateEnvironmentCreate
@@ -765,45 +866,289 @@ func resourceJelasticCreateEnvironmentUpdate(ctx context.Context, d *schema.Reso
 func resourceJelasticCreateEnvironmentDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	// Statement of m and type assertion with *CLient
 	m := meta.(*Client)
-	// Statement of http client to do API requests
-	client := m.HTTPClient
-	session := m.Token
 
 	// Declare diag variable for debugging
 	var diags diag.Diagnostics
 
-	// Define REST URL
-	u := *m.BaseUrl
-	u.Path += API_ENV_CONTROL_DELETEENV_ENDPOINT
-	urlStr := u.String()
-
-	var req_config JelasticRequest = JelasticRequest{
-		Method:  http.MethodPost,
-		Headers: client_headers,
-	}
-
-	req_config.Query = url.Values{
+	_, err := m.doJelasticRequest(API_ENV_CONTROL_DELETEENV_ENDPOINT, url.Values{
 		"envName": {d.Id()},
-		"session": {session},
-	}
-	req_config.Body = strings.NewReader(req_config.Query.Encode())
-	req, _ := http.NewRequest(req_config.Method, urlStr, req_config.Body)
-	req.Header = req_config.Headers
-
-	resp, err := client.Do(req)
+	})
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  fmt.Sprintf("Unable to delete environment %s", d.Id()),
-			Detail:   fmt.Sprintf("The environment %s doesn't exist", d.Id()),
+			Detail:   err.Error(),
 		})
 		return diags
 	}
-	defer resp.Body.Close()
 
 	return nil
 }
 
+// Resolve the defaults of the provider, check the permissions of the access
+// token and the nodes blocks against the templates and the quotas of the
+// platform, and estimate the cost of the environment
+func resourceJelasticCreateEnvironmentCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Defaults of the provider are resolved in the plan from the configured
+	// values, so that a change of the defaults shows up like a change of the
+	// configuration
+	region := d.Get("environment.0.region").(string)
+	if region == "" {
+		region = m.DefaultRegion
+	}
+	if region != "" && region != d.Get("region").(string) {
+		if err := d.SetNew("region", region); err != nil {
+			return err
+		}
+	}
+	envgroups_all := d.Get("envgroups").(string)
+	if envgroups_all == "" {
+		envgroups_all = m.DefaultEnvgroups
+	}
+	if envgroups_all != d.Get("envgroups_all").(string) {
+		if err := d.SetNew("envgroups_all", envgroups_all); err != nil {
+			return err
+		}
+	}
+	tf_nodes := d.Get("nodes").([]interface{})
+	node_labels_all := expandNodeLabelsAll(m.DefaultNodeLabels, tf_nodes)
+	for _, node_labels := range node_labels_all {
+		if len(node_labels.(map[string]interface{})["labels"].(map[string]interface{})) > 0 {
+			if err := m.requireFeature(FEATURE_NODE_LABELS); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	if !reflect.DeepEqual(node_labels_all, d.Get("node_labels_all")) {
+		if err := d.SetNew("node_labels_all", node_labels_all); err != nil {
+			return err
+		}
+	}
+
+	// Methods called by the apply
+	endpoints := []string{API_ENV_CONTROL_GETENVINFO_ENDPOINT}
+	if d.Id() == "" {
+		endpoints = append(endpoints, API_ENV_CONTROL_GETREGIONS_ENDPOINT, API_ENV_CONTROL_CREATEENV_ENDPOINT)
+	}
+	if d.HasChange("envgroups_all") && d.Id() != "" {
+		endpoints = append(endpoints, API_ENV_CONTROL_SETENVGROUP_ENDPOINT)
+	}
+	if d.HasChange("region") && d.Id() != "" {
+		endpoints = append(endpoints, API_ENV_CONTROL_MIGRATE_ENDPOINT)
+	}
+	if err := m.checkEndpointsAllowed(endpoints...); err != nil {
+		return err
+	}
+
+	if !d.HasChange("nodes") {
+		return nil
+	}
+
+	node_types, err := getNodeTypes(m)
+	if err != nil {
+		// The catalogue is a convenience, the API still checks nodetype on apply
+		log.Printf("[WARN] Unable to get node types, nodetype isn't validated: %s", err)
+	} else {
+		for i, tf_node := range tf_nodes {
+			nodetype := tf_node.(map[string]interface{})["nodetype"].(string)
+			if nodetype == "" {
+				// Not known yet
+				continue
+			}
+			if err := validateNodeType(node_types, nodetype); err != nil {
+				return fmt.Errorf("nodes.%d: %s", i, err)
+			}
+		}
+	}
+
+	quotas, err := getAccountQuotas(m)
+	if err != nil {
+		log.Printf("[WARN] Unable to get quotas, nodes aren't checked: %s", err)
+		_ = d.SetNewComputed("quota_warnings")
+	} else {
+		warnings, err := checkCreateEnvironmentQuotas(quotas, tf_nodes)
+		if err != nil {
+			return err
+		}
+		if err := d.SetNew("quota_warnings", warnings); err != nil {
+			return err
+		}
+	}
+
+	prices, err := getPrices(m)
+	if err != nil {
+		log.Printf("[WARN] Unable to get prices, cost isn't estimated: %s", err)
+		_ = d.SetNewComputed("estimated_hourly_cost")
+		_ = d.SetNewComputed("estimated_monthly_cost")
+		return nil
+	}
+	hourly_cost := estimateCreateEnvironmentCost(prices, tf_nodes)
+	if err := d.SetNew("estimated_hourly_cost", hourly_cost); err != nil {
+		return err
+	}
+	return d.SetNew("estimated_monthly_cost", hourly_cost*HOURS_BY_MONTH)
+}
+
+// Number of public IPs used by a nodes block
+func nodesExtIpCount(tf_node map[string]interface{}) int {
+	count := tf_node["count"].(int)
+	extips := tf_node["extip_count"].(int)
+	if extips == 0 && tf_node["extip"].(bool) {
+		extips = 1
+	}
+	if tf_node["extipv6"].(bool) {
+		extips++
+	}
+	return count * extips
+}
+
+// Return an error when the nodes blocks exceed a quota of the account and
+// the quotas which are nearly reached. Negative quotas are unlimited, a quota
+// of 0 forbids the resource and missing quotas aren't checked.
+func checkCreateEnvironmentQuotas(quotas map[string]interface{}, tf_nodes []interface{}) ([]string, error) {
+	warnings := []string{}
+	check := func(attribute string, field string, value int) error {
+		limit, ok := quotas[attribute].(int)
+		if !ok || limit < 0 {
+			return nil
+		}
+		if value > limit {
+			return fmt.Errorf("%s is %d but %s of the account is %d", field, value, attribute, limit)
+		}
+		if value > 0 && float64(value) >= float64(limit)*QUOTA_WARNING_RATIO {
+			warning := fmt.Sprintf("%s is %d, close to %s of the account (%d)", field, value, attribute, limit)
+			log.Printf("[WARN] %s", warning)
+			warnings = append(warnings, warning)
+		}
+		return nil
+	}
+
+	total_nodes := 0
+	total_cloudlets := 0
+	total_extips := 0
+	for i, tf_node := range tf_nodes {
+		tf_node_map := tf_node.(map[string]interface{})
+		if err := check("max_cloudlets_per_node", fmt.Sprintf("nodes.%d.flexiblecloudlets", i), tf_node_map["flexiblecloudlets"].(int)); err != nil {
+			return nil, err
+		}
+		if err := check("max_cloudlets_per_node", fmt.Sprintf("nodes.%d.fixedcloudlets", i), tf_node_map["fixedcloudlets"].(int)); err != nil {
+			return nil, err
+		}
+		// disklimit is in GB, the quota in MB
+		if err := check("max_disk", fmt.Sprintf("nodes.%d.disklimit (MB)", i), tf_node_map["disklimit"].(int)*1024); err != nil {
+			return nil, err
+		}
+		// Flexible cloudlets are the limit of a node, fixed cloudlets included
+		cloudlets := tf_node_map["flexiblecloudlets"].(int)
+		if fixed := tf_node_map["fixedcloudlets"].(int); fixed > cloudlets {
+			cloudlets = fixed
+		}
+		total_nodes += tf_node_map["count"].(int)
+		total_cloudlets += tf_node_map["count"].(int) * cloudlets
+		total_extips += nodesExtIpCount(tf_node_map)
+	}
+	if err := check("max_nodes", "Number of nodes", total_nodes); err != nil {
+		return nil, err
+	}
+	if err := check("max_cloudlets", "Number of cloudlets", total_cloudlets); err != nil {
+		return nil, err
+	}
+	if err := check("max_extips", "Number of public IPs", total_extips); err != nil {
+		return nil, err
+	}
+	return warnings, nil
+}
+
+// Hourly cost of the nodes blocks. Flexible cloudlets are billed on usage,
+// they are counted as fully used so the estimation is an upper bound.
+func estimateCreateEnvironmentCost(prices map[string]float64, tf_nodes []interface{}) float64 {
+	cost := 0.0
+	for _, tf_node := range tf_nodes {
+		tf_node_map := tf_node.(map[string]interface{})
+		count := float64(tf_node_map["count"].(int))
+		fixed := float64(tf_node_map["fixedcloudlets"].(int))
+		flexible := float64(tf_node_map["flexiblecloudlets"].(int)) - fixed
+		if flexible < 0 {
+			flexible = 0
+		}
+		cost += count * fixed * prices[PRICING_RESOURCE_FIXED_CLOUDLET]
+		cost += count * flexible * prices[PRICING_RESOURCE_FLEXIBLE_CLOUDLET]
+		cost += count * float64(tf_node_map["disklimit"].(int)) * prices[PRICING_RESOURCE_DISK]
+		cost += float64(nodesExtIpCount(tf_node_map)) * prices[PRICING_RESOURCE_IP]
+	}
+	return cost
+}
+
+// Hourly price of a unit of each resource, from the first tier of the
+// tariffs of the account
+func getPrices(m *Client) (map[string]float64, error) {
+	result, err := m.doJelasticRequest(API_BILLING_PRICING_GETPRICING_ENDPOINT, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[string]float64)
+	pricings, _ := result["array"].([]interface{})
+	for _, pricing := range pricings {
+		tariffs, _ := pricing.(map[string]interface{})["tariffs"].([]interface{})
+		for _, tariff := range tariffs {
+			tariff_map := tariff.(map[string]interface{})
+			resource, _ := tariff_map["resource"].(string)
+			if _, ok := prices[resource]; ok {
+				continue
+			}
+			if price, ok := tariff_map["price"].(float64); ok {
+				prices[resource] = price
+			}
+		}
+	}
+	return prices, nil
+}
+
+// Labels of each nodes block with the default labels of the provider
+func expandNodeLabelsAll(default_labels map[string]string, tf_nodes []interface{}) []interface{} {
+	node_labels_all := []interface{}{}
+	for _, tf_node := range tf_nodes {
+		tf_node_map := tf_node.(map[string]interface{})
+		labels := make(map[string]interface{})
+		for key, value := range default_labels {
+			labels[key] = value
+		}
+		tf_labels, _ := tf_node_map["labels"].(map[string]interface{})
+		for key, value := range tf_labels {
+			labels[key] = value
+		}
+		node_labels_all = append(node_labels_all, map[string]interface{}{
+			"nodegroup": tf_node_map["nodegroup"],
+			"labels":    labels,
+		})
+	}
+	return node_labels_all
+}
+
+// Set the labels of each node group
+func applyNodeLabels(m *Client, env_name string, node_labels_all []interface{}) error {
+	for _, node_labels := range node_labels_all {
+		node_labels_map := node_labels.(map[string]interface{})
+		data, _ := json.Marshal(map[string]interface{}{
+			"labels": node_labels_map["labels"],
+		})
+		_, err := m.doJelasticRequest(API_ENV_NODEGROUP_APPLYDATA_ENDPOINT, url.Values{
+			"envName":   {env_name},
+			"nodeGroup": {node_labels_map["nodegroup"].(string)},
+			"data":      {string(data)},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func initObjRefsWithPreallocation(n int) []*Nodes {
 	objs := make([]Nodes, n)
 	refs := make([]*Nodes, 0, n)
@@ -832,3 +1177,128 @@ func flattenCreateEnvironmentEnvironmentData(env map[string]interface{}) interfa
 	})
 	return flatten_environment
 }
+
+// Split public IPs of a getenvinfo node in IPv4 and IPv6 addresses
+func splitNodeExtIps(node map[string]interface{}) ([]string, []string) {
+	extipv4 := []string{}
+	extipv6 := []string{}
+	extiplist, _ := node["extIPs"].([]interface{})
+	for _, extip := range extiplist {
+		ip := net.ParseIP(extip.(string))
+		if ip == nil {
+			continue
+		}
+		if ip.To4() != nil {
+			extipv4 = append(extipv4, extip.(string))
+		} else {
+			extipv6 = append(extipv6, extip.(string))
+		}
+	}
+	return extipv4, extipv6
+}
+
+// Fill the public IPs of each nodes block from getenvinfo nodes
+func setCreateEnvironmentNodesExtIps(tf_nodes []interface{}, nodes []interface{}) []interface{} {
+	for _, tf_node := range tf_nodes {
+		tf_node_data := tf_node.(map[string]interface{})
+		extipv4 := []string{}
+		extipv6 := []string{}
+		for _, node := range nodes {
+			node_map := node.(map[string]interface{})
+			if node_map["nodeGroup"] != tf_node_data["nodegroup"] {
+				continue
+			}
+			node_extipv4, node_extipv6 := splitNodeExtIps(node_map)
+			extipv4 = append(extipv4, node_extipv4...)
+			extipv6 = append(extipv6, node_extipv6...)
+		}
+		tf_node_data["extip_addresses"] = extipv4
+		tf_node_data["extipv6_addresses"] = extipv6
+	}
+	return tf_nodes
+}
+
+// Attach or detach public IPs of each node of a nodes block until
+// they match extip, extipv6 and extip_count
+func syncNodeGroupExtIps(m *Client, env_name string, tf_node map[string]interface{}) error {
+	wanted_extipv4 := tf_node["extip_count"].(int)
+	if wanted_extipv4 == 0 && tf_node["extip"].(bool) {
+		wanted_extipv4 = 1
+	}
+	wanted_extipv6 := 0
+	if tf_node["extipv6"].(bool) {
+		wanted_extipv6 = 1
+	}
+
+	result, err := m.doJelasticRequest(API_ENV_CONTROL_GETENVINFO_ENDPOINT, url.Values{
+		"envName": {env_name},
+		"lazy":    {"false"},
+	})
+	if err != nil {
+		return err
+	}
+	nodes, _ := result["nodes"].([]interface{})
+	for _, node := range nodes {
+		node_map := node.(map[string]interface{})
+		if node_map["nodeGroup"] != tf_node["nodegroup"] {
+			continue
+		}
+		node_id := strconv.Itoa(int(node_map["id"].(float64)))
+		extipv4, extipv6 := splitNodeExtIps(node_map)
+		for _, ip_type := range []struct {
+			name   string
+			ips    []string
+			wanted int
+		}{
+			{"ipv4", extipv4, wanted_extipv4},
+			{"ipv6", extipv6, wanted_extipv6},
+		} {
+			for i := len(ip_type.ips); i < ip_type.wanted; i++ {
+				_, err := m.doJelasticRequest(API_ENV_CONTROL_ATTACHEXTIP_ENDPOINT, url.Values{
+					"envName": {env_name},
+					"nodeid":  {node_id},
+					"type":    {ip_type.name},
+				})
+				if err != nil {
+					return err
+				}
+			}
+			for i := len(ip_type.ips); i > ip_type.wanted; i-- {
+				_, err := m.doJelasticRequest(API_ENV_CONTROL_DETACHEXTIP_ENDPOINT, url.Values{
+					"envName": {env_name},
+					"nodeid":  {node_id},
+					"ip":      {ip_type.ips[i-1]},
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// One entry by node returned by getenvinfo
+func flattenCreateEnvironmentNodeInstances(nodes []interface{}, uid float64, ssh_gateway string) interface{} {
+	flatten_instances := []map[string]interface{}{}
+	for _, node := range nodes {
+		node_map := node.(map[string]interface{})
+		node_id := int(node_map["id"].(float64))
+		flatten_instance := map[string]interface{}{
+			"id":       node_id,
+			"ssh_user": fmt.Sprintf("%d-%d", node_id, int(uid)),
+			"ssh_host": ssh_gateway,
+			"ssh_port": SSH_GATEWAY_PORT,
+		}
+		flatten_instance["nodegroup"], _ = node_map["nodeGroup"].(string)
+		flatten_instance["intip"], _ = node_map["intIP"].(string)
+		flatten_instance["url"], _ = node_map["url"].(string)
+		flatten_instance["adminurl"], _ = node_map["adminUrl"].(string)
+		flatten_instance["ismaster"], _ = node_map["ismaster"].(bool)
+		if status, ok := node_map["status"].(float64); ok {
+			flatten_instance["status"] = int(status)
+		}
+		flatten_instances = append(flatten_instances, flatten_instance)
+	}
+	return flatten_instances
+}
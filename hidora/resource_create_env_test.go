@@ -0,0 +1,85 @@
+package hidora
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckCreateEnvironmentQuotas(t *testing.T) {
+	node := func(count int, fixed int, flexible int, extip bool) map[string]interface{} {
+		return map[string]interface{}{
+			"count":             count,
+			"fixedcloudlets":    fixed,
+			"flexiblecloudlets": flexible,
+			"disklimit":         10,
+			"extip":             extip,
+			"extip_count":       0,
+			"extipv6":           false,
+		}
+	}
+	cases := []struct {
+		name     string
+		quotas   map[string]interface{}
+		nodes    []interface{}
+		err      string
+		warnings int
+	}{
+		{
+			name:   "unlimited",
+			quotas: map[string]interface{}{"max_cloudlets": -1, "max_nodes": -1, "max_extips": -1},
+			nodes:  []interface{}{node(4, 2, 16, true)},
+		},
+		{
+			name:   "missing quotas",
+			quotas: map[string]interface{}{},
+			nodes:  []interface{}{node(4, 2, 16, true)},
+		},
+		{
+			name:   "no public IP allowed",
+			quotas: map[string]interface{}{"max_extips": 0},
+			nodes:  []interface{}{node(1, 1, 4, true)},
+			err:    "Number of public IPs is 1 but max_extips of the account is 0",
+		},
+		{
+			name:   "no public IP used",
+			quotas: map[string]interface{}{"max_extips": 0},
+			nodes:  []interface{}{node(1, 1, 4, false)},
+		},
+		{
+			name:   "count times cloudlets",
+			quotas: map[string]interface{}{"max_cloudlets_per_node": 16, "max_cloudlets": 40},
+			nodes:  []interface{}{node(2, 2, 16, false), node(1, 10, 8, false)},
+			err:    "Number of cloudlets is 42 but max_cloudlets of the account is 40",
+		},
+		{
+			name:   "cloudlets per node",
+			quotas: map[string]interface{}{"max_cloudlets_per_node": 8},
+			nodes:  []interface{}{node(1, 1, 16, false)},
+			err:    "nodes.0.flexiblecloudlets is 16",
+		},
+		{
+			name:     "nearly reached",
+			quotas:   map[string]interface{}{"max_nodes": 5, "max_cloudlets": 80},
+			nodes:    []interface{}{node(4, 2, 16, false)},
+			warnings: 2,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			warnings, err := checkCreateEnvironmentQuotas(c.quotas, c.nodes)
+			if c.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if len(warnings) != c.warnings {
+					t.Fatalf("expected %d warnings, got %v", c.warnings, warnings)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("expected error %q, got %v", c.err, err)
+			}
+		})
+	}
+}
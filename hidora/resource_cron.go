@@ -0,0 +1,382 @@
+package hidora
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+type CronField struct {
+	Name  string
+	Min   int
+	Max   int
+	Names []string // Names accepted instead of numbers, starting at Min
+}
+
+const (
+	API_ENV_FILE_READ_ENDPOINT  string = "environment/file/rest/read"
+	API_ENV_FILE_WRITE_ENDPOINT string = "environment/file/rest/write"
+	API_RESULT_FILE_NOT_FOUND   int    = 4109 // Path doesn't exist on the node
+	CRONTAB_DIRECTORY           string = "/var/spool/cron/"
+	CRONTAB_MARKER              string = "# hidora:"
+)
+
+var cron_fields = []CronField{
+	{Name: "minute", Min: 0, Max: 59},
+	{Name: "hour", Min: 0, Max: 23},
+	{Name: "day of month", Min: 1, Max: 31},
+	{Name: "month", Min: 1, Max: 12, Names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{Name: "day of week", Min: 0, Max: 7, Names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+var cron_macros = []string{"@reboot", "@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly"}
+
+// A crontab is read then written entirely, entries of the same crontab
+// must not be updated at the same time
+var crontab_locks sync.Map
+
+func resourceHidoraCron() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceJelasticCronCreate,
+		ReadContext:   resourceJelasticCronRead,
+		UpdateContext: resourceJelasticCronUpdate,
+		DeleteContext: resourceJelasticCronDelete,
+		CustomizeDiff: checkResourceEndpoints(
+			API_ENV_FILE_READ_ENDPOINT,
+			API_ENV_FILE_WRITE_ENDPOINT,
+		),
+		SchemaVersion: 0,
+		Schema: map[string]*schema.Schema{
+			"env_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the environment",
+			},
+			"nodegroup": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Node group where the crontab is written",
+			},
+			"user": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "root",
+				ForceNew:     true,
+				ValidateFunc: validateCronUser,
+				Description:  "Owner of the crontab",
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateCronName,
+				Description:  "Unique name of the entry in the crontab",
+			},
+			"schedule": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateFunc:     validateCronSchedule,
+				DiffSuppressFunc: suppressCronScheduleDiff,
+				Description:      "Cron expression with 5 fields or a macro like @daily",
+			},
+			"command": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateCronCommand,
+				Description:  "Command run by cron, on a single line",
+			},
+		},
+	}
+}
+
+func resourceJelasticCronCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	if err := writeCronEntry(meta.(*Client), d, true); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to write cron entry",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	d.SetId(fmt.Sprintf("%s/%s/%s/%s",
+		d.Get("env_name").(string),
+		d.Get("nodegroup").(string),
+		d.Get("user").(string),
+		d.Get("name").(string)))
+
+	return resourceJelasticCronRead(ctx, d, meta)
+}
+
+func resourceJelasticCronRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	crontab, err := readCrontab(m, d)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to read crontab",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	entries := parseCrontab(crontab)
+	entry, ok := entries[d.Get("name").(string)]
+	if !ok {
+		// Entry has been removed outside of Terraform
+		d.SetId("")
+		return diags
+	}
+	_ = d.Set("schedule", entry[0])
+	_ = d.Set("command", entry[1])
+
+	return diags
+}
+
+func resourceJelasticCronUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	if err := writeCronEntry(meta.(*Client), d, true); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to write cron entry",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	return resourceJelasticCronRead(ctx, d, meta)
+}
+
+func resourceJelasticCronDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	if err := writeCronEntry(meta.(*Client), d, false); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to remove cron entry",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	return diags
+}
+
+func readCrontab(m *Client, d *schema.ResourceData) (string, error) {
+	result, err := m.doJelasticRequest(API_ENV_FILE_READ_ENDPOINT, url.Values{
+		"envName":   {d.Get("env_name").(string)},
+		"nodeGroup": {d.Get("nodegroup").(string)},
+		"path":      {CRONTAB_DIRECTORY + d.Get("user").(string)},
+	})
+	if isJelasticResult(err, API_RESULT_FILE_NOT_FOUND) {
+		// Crontab doesn't exist until the first entry is written
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	body, _ := result["body"].(string)
+	return body, nil
+}
+
+// Replace, add or remove the entry of the resource then write the crontab
+// on every node of the node group
+func writeCronEntry(m *Client, d *schema.ResourceData, present bool) error {
+	crontab_path := CRONTAB_DIRECTORY + d.Get("user").(string)
+	lock_key := fmt.Sprintf("%s/%s/%s", d.Get("env_name").(string), d.Get("nodegroup").(string), crontab_path)
+	lock, _ := crontab_locks.LoadOrStore(lock_key, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	crontab, err := readCrontab(m, d)
+	if err != nil {
+		return err
+	}
+
+	name := d.Get("name").(string)
+	var lines []string
+	skip_next := false
+	for _, line := range strings.Split(strings.TrimRight(crontab, "\n"), "\n") {
+		if skip_next {
+			skip_next = false
+			continue
+		}
+		if strings.TrimSpace(line) == CRONTAB_MARKER+name {
+			skip_next = true
+			continue
+		}
+		if line != "" || len(lines) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	if present {
+		lines = append(lines,
+			CRONTAB_MARKER+name,
+			fmt.Sprintf("%s %s", d.Get("schedule").(string), d.Get("command").(string)))
+	}
+
+	_, err = m.doJelasticRequest(API_ENV_FILE_WRITE_ENDPOINT, url.Values{
+		"envName":      {d.Get("env_name").(string)},
+		"nodeGroup":    {d.Get("nodegroup").(string)},
+		"path":         {crontab_path},
+		"body":         {strings.Join(lines, "\n") + "\n"}, // cron ignores a last line without newline
+		"isAppendMode": {"false"},
+	})
+	return err
+}
+
+// Entries written by the provider, by name, as schedule and command
+func parseCrontab(crontab string) map[string][2]string {
+	entries := make(map[string][2]string)
+	lines := strings.Split(crontab, "\n")
+	for i := 0; i < len(lines)-1; i++ {
+		line := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(line, CRONTAB_MARKER) {
+			continue
+		}
+		fields := strings.Fields(lines[i+1])
+		schedule_len := 5
+		if len(fields) > 0 && strings.HasPrefix(fields[0], "@") {
+			schedule_len = 1
+		}
+		if len(fields) <= schedule_len {
+			continue
+		}
+		// Command is kept as written, only the schedule is split in fields
+		command := strings.TrimSpace(lines[i+1])
+		for _, field := range fields[:schedule_len] {
+			command = strings.TrimSpace(strings.TrimPrefix(command, field))
+		}
+		entries[strings.TrimPrefix(line, CRONTAB_MARKER)] = [2]string{
+			strings.Join(fields[:schedule_len], " "),
+			command,
+		}
+		i++
+	}
+	return entries
+}
+
+func validateCronName(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	is_name_valid := regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`).MatchString
+	if !is_name_valid(value) {
+		errors = append(errors, fmt.Errorf("%q can only contain alphanumeric characters, dots, dashes and underscores, got: %s", k, value))
+	}
+	return
+}
+
+// The user names the crontab file, it must not leave CRONTAB_DIRECTORY
+func validateCronUser(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	is_user_valid := regexp.MustCompile(`^[a-z_][a-z0-9_-]{0,31}$`).MatchString
+	if !is_user_valid(value) {
+		errors = append(errors, fmt.Errorf("%q must be a POSIX user name, got: %s", k, value))
+	}
+	return
+}
+
+// An entry is a single line of the crontab, a newline would add a line
+// which isn't managed by the provider
+func validateCronCommand(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if strings.ContainsAny(value, "\r\n") {
+		errors = append(errors, fmt.Errorf("%q can't contain newlines", k))
+	} else if strings.TrimSpace(value) == "" {
+		errors = append(errors, fmt.Errorf("%q can't be empty", k))
+	}
+	return
+}
+
+// Schedules only differing by spaces are the same
+func suppressCronScheduleDiff(k, old, new string, d *schema.ResourceData) bool {
+	return strings.Join(strings.Fields(old), " ") == strings.Join(strings.Fields(new), " ")
+}
+
+func validateCronSchedule(v interface{}, k string) (ws []string, errors []error) {
+	value := strings.TrimSpace(v.(string))
+	if strings.HasPrefix(value, "@") {
+		for _, macro := range cron_macros {
+			if value == macro {
+				return
+			}
+		}
+		errors = append(errors, fmt.Errorf("%q has an unknown macro %s, expected one of %s", k, value, strings.Join(cron_macros, ", ")))
+		return
+	}
+
+	fields := strings.Fields(value)
+	if len(fields) != len(cron_fields) {
+		errors = append(errors, fmt.Errorf("%q must have %d fields, got %d: %s", k, len(cron_fields), len(fields), value))
+		return
+	}
+	for i, field := range fields {
+		if err := validateCronField(field, cron_fields[i]); err != nil {
+			errors = append(errors, fmt.Errorf("%q has an invalid %s field: %s", k, cron_fields[i].Name, err))
+		}
+	}
+	return
+}
+
+// A field is a list of *, values or ranges, each one with an optional step
+func validateCronField(field string, cron_field CronField) error {
+	for _, item := range strings.Split(field, ",") {
+		item_range := item
+		if step_index := strings.Index(item, "/"); step_index >= 0 {
+			item_range = item[:step_index]
+			step, err := strconv.Atoi(item[step_index+1:])
+			if err != nil || step < 1 {
+				return fmt.Errorf("invalid step in %q", item)
+			}
+		}
+		if item_range == "*" {
+			continue
+		}
+		bounds := strings.SplitN(item_range, "-", 2)
+		values := make([]int, len(bounds))
+		for i, bound := range bounds {
+			value, err := parseCronValue(bound, cron_field)
+			if err != nil {
+				return err
+			}
+			values[i] = value
+		}
+		if len(values) == 2 && values[0] > values[1] {
+			return fmt.Errorf("range %q is reversed", item_range)
+		}
+	}
+	return nil
+}
+
+func parseCronValue(value string, cron_field CronField) (int, error) {
+	for i, name := range cron_field.Names {
+		if strings.ToLower(value) == name {
+			return cron_field.Min + i, nil
+		}
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", value)
+	}
+	if number < cron_field.Min || number > cron_field.Max {
+		return 0, fmt.Errorf("%d is out of range %d-%d", number, cron_field.Min, cron_field.Max)
+	}
+	return number, nil
+}
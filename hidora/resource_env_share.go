@@ -0,0 +1,288 @@
+package hidora
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	API_ENV_CONTROL_ADDAPPACCESS_ENDPOINT        string = "environment/control/rest/addappaccess"
+	API_ENV_CONTROL_EDITAPPACCESS_ENDPOINT       string = "environment/control/rest/editappaccess"
+	API_ENV_CONTROL_REMOVEAPPACCESS_ENDPOINT     string = "environment/control/rest/removeappaccess"
+	API_ENV_CONTROL_GETAPPACCESSLIST_ENDPOINT    string = "environment/control/rest/getappaccesslist"
+	API_ENV_CONTROL_SENDTRANSFERREQUEST_ENDPOINT string = "environment/control/rest/sendtransferrequest"
+	ENV_SHARE_ROLE_CUSTOM                        string = "custom"
+)
+
+func resourceHidoraEnvShare() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceJelasticEnvShareCreate,
+		ReadContext:   resourceJelasticEnvShareRead,
+		UpdateContext: resourceJelasticEnvShareUpdate,
+		DeleteContext: resourceJelasticEnvShareDelete,
+		CustomizeDiff: resourceJelasticEnvShareCustomizeDiff,
+		SchemaVersion: 0,
+		Schema: map[string]*schema.Schema{
+			"env_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the shared environment",
+			},
+			"email": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"email", "group_id"},
+				Description:  "Email of the collaborator",
+			},
+			"group_id": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"email", "group_id"},
+				Description:  "ID of the collaborator group",
+			},
+			"role": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "viewer",
+				ValidateFunc: validation.StringInSlice([]string{"viewer", "admin", ENV_SHARE_ROLE_CUSTOM}, false),
+				Description:  "viewer, admin or custom",
+			},
+			"permissions": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Permissions given with the custom role",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"transfer_ownership": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				ForceNew:    true,
+				Description: "Send a request to transfer the ownership of the environment to email instead of sharing it",
+			},
+		},
+	}
+}
+
+func resourceJelasticEnvShareCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	if d.Get("transfer_ownership").(bool) {
+		_, err := m.doJelasticRequest(API_ENV_CONTROL_SENDTRANSFERREQUEST_ENDPOINT, url.Values{
+			"envName": {d.Get("env_name").(string)},
+			"email":   {d.Get("email").(string)},
+		})
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to send transfer request",
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		d.SetId(fmt.Sprintf("%s/transfer/%s", d.Get("env_name").(string), d.Get("email").(string)))
+		return diags
+	}
+
+	_, err := m.doJelasticRequest(API_ENV_CONTROL_ADDAPPACCESS_ENDPOINT, expandEnvShareAccess(d))
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to share environment",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	d.SetId(fmt.Sprintf("%s/%s", d.Get("env_name").(string), envShareCollaborator(d)))
+
+	return resourceJelasticEnvShareRead(ctx, d, meta)
+}
+
+func resourceJelasticEnvShareRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	// A transfer request can't be read back
+	if d.Get("transfer_ownership").(bool) {
+		return diags
+	}
+
+	result, err := m.doJelasticRequest(API_ENV_CONTROL_GETAPPACCESSLIST_ENDPOINT, url.Values{
+		"envName": {d.Get("env_name").(string)},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get collaborators of environment",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	collaborator := envShareCollaborator(d)
+	accesses, _ := result["array"].([]interface{})
+	for _, access := range accesses {
+		access_map := access.(map[string]interface{})
+		access_email, _ := access_map["email"].(string)
+		access_group_id, _ := access_map["groupId"].(float64)
+		if access_email != collaborator && strconv.Itoa(int(access_group_id)) != collaborator {
+			continue
+		}
+		if role, ok := access_map["role"].(string); ok {
+			_ = d.Set("role", strings.ToLower(role))
+		}
+		if permissions, ok := access_map["permissions"].([]interface{}); ok {
+			_ = d.Set("permissions", permissions)
+		}
+		return diags
+	}
+
+	// Access has been removed outside of Terraform
+	d.SetId("")
+	return diags
+}
+
+func resourceJelasticEnvShareUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	// A transfer request has no access to edit, CustomizeDiff replaces it
+	if d.Get("transfer_ownership").(bool) {
+		return diags
+	}
+
+	_, err := m.doJelasticRequest(API_ENV_CONTROL_EDITAPPACCESS_ENDPOINT, expandEnvShareAccess(d))
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to edit access to environment",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	return resourceJelasticEnvShareRead(ctx, d, meta)
+}
+
+func resourceJelasticEnvShareDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	// Ownership can't be taken back once transferred
+	if d.Get("transfer_ownership").(bool) {
+		return diags
+	}
+
+	req_query := expandEnvShareAccess(d)
+	req_query.Del("role")
+	req_query.Del("permissions")
+	_, err := m.doJelasticRequest(API_ENV_CONTROL_REMOVEAPPACCESS_ENDPOINT, req_query)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Unable to remove access %s", d.Id()),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	return diags
+}
+
+func resourceJelasticEnvShareCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Get("transfer_ownership").(bool) {
+		if err := meta.(*Client).checkEndpointsAllowed(API_ENV_CONTROL_SENDTRANSFERREQUEST_ENDPOINT); err != nil {
+			return err
+		}
+	} else {
+		if err := meta.(*Client).requireFeature(FEATURE_COLLABORATION); err != nil {
+			return err
+		}
+		if err := meta.(*Client).checkEndpointsAllowed(
+			API_ENV_CONTROL_ADDAPPACCESS_ENDPOINT,
+			API_ENV_CONTROL_GETAPPACCESSLIST_ENDPOINT,
+			API_ENV_CONTROL_EDITAPPACCESS_ENDPOINT,
+			API_ENV_CONTROL_REMOVEAPPACCESS_ENDPOINT,
+		); err != nil {
+			return err
+		}
+	}
+	// Values coming from other resources are only checked once known
+	if d.Get("transfer_ownership").(bool) && d.NewValueKnown("email") && d.Get("email").(string) == "" {
+		return fmt.Errorf("transfer_ownership needs the email of the new owner")
+	}
+	// Access of a transfer request can't be edited, it is sent again instead
+	if d.Get("transfer_ownership").(bool) && d.Id() != "" {
+		for _, key := range []string{"role", "permissions"} {
+			if d.HasChange(key) {
+				if err := d.ForceNew(key); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if !d.NewValueKnown("role") || !d.NewValueKnown("permissions") {
+		return nil
+	}
+	if d.Get("role").(string) == ENV_SHARE_ROLE_CUSTOM && d.Get("permissions").(*schema.Set).Len() == 0 {
+		return fmt.Errorf("permissions must be set with the custom role")
+	}
+	if d.Get("role").(string) != ENV_SHARE_ROLE_CUSTOM && d.Get("permissions").(*schema.Set).Len() > 0 {
+		return fmt.Errorf("permissions can only be set with the custom role")
+	}
+	return nil
+}
+
+// Email or group ID of the collaborator
+func envShareCollaborator(d *schema.ResourceData) string {
+	if email := d.Get("email").(string); email != "" {
+		return email
+	}
+	return strconv.Itoa(d.Get("group_id").(int))
+}
+
+// Parameters of addappaccess and editappaccess API methods
+func expandEnvShareAccess(d *schema.ResourceData) url.Values {
+	req_query := url.Values{
+		"envName": {d.Get("env_name").(string)},
+		"role":    {strings.ToUpper(d.Get("role").(string))},
+	}
+	if email := d.Get("email").(string); email != "" {
+		req_query.Set("email", email)
+	} else {
+		req_query.Set("groupId", strconv.Itoa(d.Get("group_id").(int)))
+	}
+	if permissions := d.Get("permissions").(*schema.Set).List(); len(permissions) > 0 {
+		permissions_list := make([]string, len(permissions))
+		for i, permission := range permissions {
+			permissions_list[i] = permission.(string)
+		}
+		req_query.Set("permissions", strings.Join(permissions_list, ","))
+	}
+	return req_query
+}
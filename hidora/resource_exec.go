@@ -0,0 +1,217 @@
+package hidora
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+type ExecCommand struct {
+	Command string `json:"command"`
+	Params  string `json:"params,omitempty"`
+}
+
+const (
+	API_ENV_CONTROL_EXECCMDBYGROUP_ENDPOINT string = "environment/control/rest/execcmdbygroup"
+	API_ENV_CONTROL_EXECCMDBYID_ENDPOINT    string = "environment/control/rest/execcmdbyid"
+)
+
+func resourceHidoraExec() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceJelasticExecCreate,
+		ReadContext:   resourceJelasticExecRead,
+		DeleteContext: resourceJelasticExecDelete,
+		CustomizeDiff: resourceJelasticExecCustomizeDiff,
+		SchemaVersion: 0,
+		Schema: map[string]*schema.Schema{
+			"env_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the environment",
+			},
+			"nodegroup": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"nodegroup", "node_id"},
+				Description:  "Run the command on every node of the node group",
+			},
+			"node_id": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"nodegroup", "node_id"},
+				Description:  "Run the command on a single node",
+			},
+			"command": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Command to run in the containers",
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Arbitrary values which run the command again when changed",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"stdout": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Standard output of the first node",
+			},
+			"stderr": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Standard error of the first node",
+			},
+			"exit_code": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Exit code of the first node",
+			},
+			"results": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Output of every node",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"node_id": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "",
+						},
+						"stdout": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"stderr": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "",
+						},
+						"exit_code": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceJelasticExecCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	results, err := execCommand(m, d.Get("env_name").(string), d.Get("nodegroup").(string), d.Get("node_id").(int), d.Get("command").(string))
+	// Results of the nodes which answered are reported along with the error
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to run command",
+			Detail:   err.Error(),
+		})
+	}
+
+	// Command isn't saved in the state when it failed so that it runs again
+	for _, result := range results {
+		if result["exit_code"].(int) != 0 {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Command failed on node %d with exit code %d", result["node_id"].(int), result["exit_code"].(int)),
+				Detail:   fmt.Sprintf("stdout:\n%s\nstderr:\n%s", result["stdout"].(string), result["stderr"].(string)),
+			})
+		}
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	d.SetId(strconv.FormatInt(time.Now().UnixNano(), 10))
+	_ = d.Set("results", results)
+	if len(results) > 0 {
+		_ = d.Set("stdout", results[0]["stdout"])
+		_ = d.Set("stderr", results[0]["stderr"])
+		_ = d.Set("exit_code", results[0]["exit_code"])
+	}
+
+	return diags
+}
+
+// Nothing to read, the outputs are those of the last run
+func resourceJelasticExecRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+func resourceJelasticExecDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId("")
+	return nil
+}
+
+// Fail the plan when the access token can't run the command
+func resourceJelasticExecCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Get("node_id").(int) != 0 {
+		return meta.(*Client).checkEndpointsAllowed(API_ENV_CONTROL_EXECCMDBYID_ENDPOINT)
+	}
+	return meta.(*Client).checkEndpointsAllowed(API_ENV_CONTROL_EXECCMDBYGROUP_ENDPOINT)
+}
+
+// Run a command on a node group or on a node when node_id isn't 0.
+// Outputs are returned by node even when the API reports an error.
+func execCommand(m *Client, env_name string, nodegroup string, node_id int, command string) ([]map[string]interface{}, error) {
+	command_list, _ := json.Marshal([]ExecCommand{{Command: command}})
+
+	endpoint := API_ENV_CONTROL_EXECCMDBYGROUP_ENDPOINT
+	req_query := url.Values{
+		"envName":     {env_name},
+		"commandList": {string(command_list)},
+		"sayYes":      {"true"},
+	}
+	if node_id != 0 {
+		endpoint = API_ENV_CONTROL_EXECCMDBYID_ENDPOINT
+		req_query.Set("nodeId", strconv.Itoa(node_id))
+	} else {
+		req_query.Set("nodeGroup", nodegroup)
+	}
+
+	result, err := m.doJelasticRequest(endpoint, req_query)
+
+	flatten_results := []map[string]interface{}{}
+	responses, _ := result["responses"].([]interface{})
+	for _, response := range responses {
+		response_map := response.(map[string]interface{})
+		flatten_result := make(map[string]interface{})
+		flatten_result["stdout"], _ = response_map["out"].(string)
+		flatten_result["stderr"], _ = response_map["errOut"].(string)
+		node_id, _ := response_map["nodeid"].(float64)
+		exit_code, _ := response_map["exitStatus"].(float64)
+		flatten_result["node_id"] = int(node_id)
+		flatten_result["exit_code"] = int(exit_code)
+		flatten_results = append(flatten_results, flatten_result)
+	}
+	return flatten_results, err
+}
+
+// Quote a value for the shell of the nodes, single quotes are closed,
+// escaped and opened again
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,341 @@
+package hidora
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+type FirewallRule struct {
+	Id        int    `json:"id,omitempty"`
+	Nodegroup string `json:"nodeGroup"`
+	Direction string `json:"direction"`
+	Name      string `json:"name"`
+	Protocol  string `json:"protocol"`
+	Ports     string `json:"ports"`
+	Src       string `json:"src"`
+	Action    string `json:"action"`
+	Priority  int    `json:"priority"`
+	Isenabled bool   `json:"isEnabled"`
+}
+
+const (
+	API_ENV_SECURITY_ADDRULE_ENDPOINT    string = "environment/security/rest/addrule"
+	API_ENV_SECURITY_EDITRULE_ENDPOINT   string = "environment/security/rest/editrule"
+	API_ENV_SECURITY_REMOVERULE_ENDPOINT string = "environment/security/rest/removerule"
+	API_ENV_SECURITY_GETRULES_ENDPOINT   string = "environment/security/rest/getrules"
+	FIREWALL_ALL                         string = "ALL"
+)
+
+func resourceHidoraFirewallRule() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceJelasticFirewallRuleCreate,
+		ReadContext:   resourceJelasticFirewallRuleRead,
+		UpdateContext: resourceJelasticFirewallRuleUpdate,
+		DeleteContext: resourceJelasticFirewallRuleDelete,
+		CustomizeDiff: checkResourceEndpoints(
+			API_ENV_SECURITY_ADDRULE_ENDPOINT,
+			API_ENV_SECURITY_GETRULES_ENDPOINT,
+			API_ENV_SECURITY_EDITRULE_ENDPOINT,
+			API_ENV_SECURITY_REMOVERULE_ENDPOINT,
+		),
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceJelasticFirewallRuleImport,
+		},
+		SchemaVersion: 0,
+		Schema: map[string]*schema.Schema{
+			"env_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the environment",
+			},
+			"nodegroup": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Node group protected by the rule",
+			},
+			"direction": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"INPUT", "OUTPUT"}, false),
+				Description:  "INPUT for inbound or OUTPUT for outbound traffic",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "",
+			},
+			"protocol": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      FIREWALL_ALL,
+				ValidateFunc: validation.StringInSlice([]string{FIREWALL_ALL, "TCP", "UDP"}, false),
+				Description:  "ALL, TCP or UDP",
+			},
+			"ports": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      FIREWALL_ALL,
+				ValidateFunc: validateFirewallPorts,
+				Description:  "ALL, a port, a range like 1000-2000 or a list of them separated by commas without spaces",
+			},
+			"src": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      FIREWALL_ALL,
+				ValidateFunc: validateFirewallSource,
+				Description:  "ALL, an IP address or a CIDR block (destination for OUTPUT rules)",
+			},
+			"action": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{"ALLOW", "DENY"}, false),
+				Description:  "ALLOW or DENY",
+			},
+			"priority": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Rules are applied from the lowest to the highest priority",
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "",
+			},
+		},
+	}
+}
+
+func resourceJelasticFirewallRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	rule_json, _ := json.Marshal(expandFirewallRule(d))
+
+	result, err := m.doJelasticRequest(API_ENV_SECURITY_ADDRULE_ENDPOINT, url.Values{
+		"envName":   {d.Get("env_name").(string)},
+		"nodeGroup": {d.Get("nodegroup").(string)},
+		"rule":      {string(rule_json)},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to add firewall rule",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	rule, _ := result["rule"].(map[string]interface{})
+	rule_id, ok := rule["id"].(float64)
+	if !ok {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to add firewall rule",
+			Detail:   "API response doesn't contain the ID of the created rule",
+		})
+		return diags
+	}
+	d.SetId(strconv.Itoa(int(rule_id)))
+
+	return resourceJelasticFirewallRuleRead(ctx, d, meta)
+}
+
+func resourceJelasticFirewallRuleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	req_query := url.Values{
+		"envName":   {d.Get("env_name").(string)},
+		"nodeGroup": {d.Get("nodegroup").(string)}, // Optional
+		"direction": {d.Get("direction").(string)}, // Optional
+	}
+	// Imported rules are searched in all node groups
+	if req_query.Get("nodeGroup") == "" {
+		req_query.Del("nodeGroup")
+	}
+	if req_query.Get("direction") == "" {
+		req_query.Del("direction")
+	}
+
+	result, err := m.doJelasticRequest(API_ENV_SECURITY_GETRULES_ENDPOINT, req_query)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get firewall rules",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	rules, _ := result["rules"].([]interface{})
+	for _, rule := range rules {
+		rule_map, _ := rule.(map[string]interface{})
+		rule_id, _ := rule_map["id"].(float64)
+		if strconv.Itoa(int(rule_id)) != d.Id() {
+			continue
+		}
+		nodegroup, _ := rule_map["nodeGroup"].(string)
+		direction, _ := rule_map["direction"].(string)
+		name, _ := rule_map["name"].(string)
+		protocol, _ := rule_map["protocol"].(string)
+		ports, _ := rule_map["ports"].(string)
+		src, _ := rule_map["src"].(string)
+		action, _ := rule_map["action"].(string)
+		priority, _ := rule_map["priority"].(float64)
+		enabled, _ := rule_map["isEnabled"].(bool)
+		_ = d.Set("nodegroup", nodegroup)
+		_ = d.Set("direction", direction)
+		_ = d.Set("name", name)
+		_ = d.Set("protocol", protocol)
+		_ = d.Set("ports", ports)
+		_ = d.Set("src", src)
+		_ = d.Set("action", action)
+		_ = d.Set("priority", int(priority))
+		_ = d.Set("enabled", enabled)
+		return diags
+	}
+
+	// Rule has been removed outside of Terraform
+	d.SetId("")
+	return diags
+}
+
+func resourceJelasticFirewallRuleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	rule := expandFirewallRule(d)
+	rule.Id, _ = strconv.Atoi(d.Id())
+	rule_json, _ := json.Marshal(rule)
+
+	_, err := m.doJelasticRequest(API_ENV_SECURITY_EDITRULE_ENDPOINT, url.Values{
+		"envName":   {d.Get("env_name").(string)},
+		"nodeGroup": {d.Get("nodegroup").(string)},
+		"rule":      {string(rule_json)},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Unable to edit firewall rule %s", d.Id()),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	return resourceJelasticFirewallRuleRead(ctx, d, meta)
+}
+
+func resourceJelasticFirewallRuleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	_, err := m.doJelasticRequest(API_ENV_SECURITY_REMOVERULE_ENDPOINT, url.Values{
+		"envName": {d.Get("env_name").(string)},
+		"id":      {d.Id()},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Unable to remove firewall rule %s", d.Id()),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	return diags
+}
+
+// Firewall rules are imported with <env_name>/<rule id>
+func resourceJelasticFirewallRuleImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	import_id := strings.SplitN(d.Id(), "/", 2)
+	if len(import_id) != 2 || import_id[0] == "" || import_id[1] == "" {
+		return nil, fmt.Errorf("unexpected format of ID (%s), expected <env_name>/<rule id>", d.Id())
+	}
+	_ = d.Set("env_name", import_id[0])
+	d.SetId(import_id[1])
+	return []*schema.ResourceData{d}, nil
+}
+
+func expandFirewallRule(d *schema.ResourceData) *FirewallRule {
+	return &FirewallRule{
+		Nodegroup: d.Get("nodegroup").(string),
+		Direction: d.Get("direction").(string),
+		Name:      d.Get("name").(string),
+		Protocol:  d.Get("protocol").(string),
+		Ports:     d.Get("ports").(string),
+		Src:       d.Get("src").(string),
+		Action:    d.Get("action").(string),
+		Priority:  d.Get("priority").(int),
+		Isenabled: d.Get("enabled").(bool),
+	}
+}
+
+func validateFirewallPorts(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if value == FIREWALL_ALL {
+		return
+	}
+	// Spaces aren't allowed, the platform would read the ports back without
+	// them
+	is_port_range := regexp.MustCompile(`^([0-9]+)(-([0-9]+))?$`).FindStringSubmatch
+	for _, ports := range strings.Split(value, ",") {
+		port_range := is_port_range(ports)
+		if port_range == nil {
+			errors = append(errors, fmt.Errorf("%q contains an invalid port or port range: %q", k, ports))
+			continue
+		}
+		for _, port := range []string{port_range[1], port_range[3]} {
+			if port == "" {
+				continue
+			}
+			if port_number, _ := strconv.Atoi(port); port_number < 1 || port_number > 65535 {
+				errors = append(errors, fmt.Errorf("%q contains a port out of range: %s", k, port))
+			}
+		}
+		if port_range[3] != "" {
+			start, _ := strconv.Atoi(port_range[1])
+			end, _ := strconv.Atoi(port_range[3])
+			if start > end {
+				errors = append(errors, fmt.Errorf("%q contains a reversed port range: %s", k, ports))
+			}
+		}
+	}
+	return
+}
+
+func validateFirewallSource(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if value == FIREWALL_ALL || net.ParseIP(value) != nil {
+		return
+	}
+	if _, _, err := net.ParseCIDR(value); err != nil {
+		errors = append(errors, fmt.Errorf("%q must be ALL, an IP address or a CIDR block, got: %s", k, value))
+	}
+	return
+}
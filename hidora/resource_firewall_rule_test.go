@@ -0,0 +1,70 @@
+package hidora
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateFirewallPorts(t *testing.T) {
+	cases := []struct {
+		name  string
+		ports string
+		err   string
+	}{
+		{name: "all", ports: "ALL"},
+		{name: "port", ports: "443"},
+		{name: "ports and ranges", ports: "80,443,8000-8080"},
+		{name: "bounds", ports: "1-65535"},
+		{name: "single port range", ports: "22-22"},
+		{name: "reversed range", ports: "2000-1000", err: "reversed port range"},
+		{name: "port 0", ports: "0", err: "port out of range"},
+		{name: "port too high", ports: "80-65536", err: "port out of range"},
+		{name: "spaces", ports: "80, 443", err: "invalid port or port range"},
+		{name: "empty", ports: "", err: "invalid port or port range"},
+		{name: "trailing comma", ports: "80,", err: "invalid port or port range"},
+		{name: "open range", ports: "8000-", err: "invalid port or port range"},
+		{name: "lowercase all", ports: "all", err: "invalid port or port range"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, errs := validateFirewallPorts(c.ports, "ports")
+			if c.err == "" {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) == 0 || !strings.Contains(errs[0].Error(), c.err) {
+				t.Fatalf("expected error %q, got %v", c.err, errs)
+			}
+		})
+	}
+}
+
+func TestValidateFirewallSource(t *testing.T) {
+	cases := []struct {
+		src   string
+		valid bool
+	}{
+		{"ALL", true},
+		{"192.168.1.10", true},
+		{"10.0.0.0/8", true},
+		{"2001:db8::1", true},
+		{"2001:db8::/32", true},
+		{"10.0.0.0/33", false},
+		{"256.0.0.1", false},
+		{" 10.0.0.1", false},
+		{"example.com", false},
+		{"", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.src, func(t *testing.T) {
+			_, errs := validateFirewallSource(c.src, "src")
+			if c.valid != (len(errs) == 0) {
+				t.Fatalf("expected valid %t, got %v", c.valid, errs)
+			}
+		})
+	}
+}
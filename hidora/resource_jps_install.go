@@ -0,0 +1,199 @@
+package hidora
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	API_MARKETPLACE_INSTALLATION_GETINFO_ENDPOINT string = "marketplace/installation/rest/getinfo"
+	API_RESULT_APP_NOT_FOUND                      int    = 2304 // Application is not installed
+)
+
+func resourceHidoraJpsInstall() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceJelasticJpsInstallCreate,
+		ReadContext:   resourceJelasticJpsInstallRead,
+		DeleteContext: resourceJelasticJpsInstallDelete,
+		CustomizeDiff: checkResourceEndpoints(
+			API_MARKETPLACE_JPS_INSTALL_ENDPOINT,
+			API_MARKETPLACE_INSTALLATION_GETINFO_ENDPOINT,
+			API_MARKETPLACE_JPS_UNINSTALL_ENDPOINT,
+		),
+		SchemaVersion: 0,
+		Schema: map[string]*schema.Schema{
+			"manifest_url": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"manifest_url", "manifest"},
+				Description:  "URL of the JPS manifest",
+			},
+			"manifest": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				ExactlyOneOf:     []string{"manifest_url", "manifest"},
+				ValidateDiagFunc: validateJpsManifestDiag,
+				Description:      "Inline JPS manifest in YAML or JSON, validated before install",
+			},
+			"settings": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Values of the settings fields declared by the manifest",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"env_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "Existing environment for an update manifest or name of the new environment for an install manifest",
+			},
+			"nodegroup": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Node group targeted by an update manifest",
+			},
+			"region": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Region of the new environment for an install manifest",
+			},
+			"app_unique_name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Unique name of the installed application",
+			},
+			"success_text": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Success text returned by the manifest",
+			},
+		},
+	}
+}
+
+func resourceJelasticJpsInstallCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	// jps parameter accepts an URL as well as the manifest itself
+	jps := d.Get("manifest_url").(string)
+	if jps == "" {
+		jps = d.Get("manifest").(string)
+	}
+
+	settings_json, _ := json.Marshal(d.Get("settings").(map[string]interface{}))
+
+	req_query := url.Values{
+		"jps":       {jps},
+		"settings":  {string(settings_json)},
+		"envName":   {d.Get("env_name").(string)},  // Optional
+		"nodeGroup": {d.Get("nodegroup").(string)}, // Optional
+		"region":    {d.Get("region").(string)},    // Optional
+	}
+	for _, key := range []string{"envName", "nodeGroup", "region"} {
+		if req_query.Get(key) == "" {
+			req_query.Del(key)
+		}
+	}
+
+	result, err := m.doJelasticRequest(API_MARKETPLACE_JPS_INSTALL_ENDPOINT, req_query)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to install JPS manifest",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	unique_name, ok := result["uniqueName"].(string)
+	if !ok {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to install JPS manifest",
+			Detail:   "API response doesn't contain the unique name of the application",
+		})
+		return diags
+	}
+	d.SetId(unique_name)
+	_ = d.Set("app_unique_name", unique_name)
+	if success_text, ok := result["successText"].(string); ok {
+		_ = d.Set("success_text", success_text)
+	}
+	if env_name, ok := result["envName"].(string); ok {
+		_ = d.Set("env_name", env_name)
+	}
+
+	return resourceJelasticJpsInstallRead(ctx, d, meta)
+}
+
+func resourceJelasticJpsInstallRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	result, err := m.doJelasticRequest(API_MARKETPLACE_INSTALLATION_GETINFO_ENDPOINT, url.Values{
+		"appUniqueName": {d.Id()},
+	})
+	if isJelasticResult(err, API_RESULT_APP_NOT_FOUND) {
+		// Application has been uninstalled outside of Terraform
+		d.SetId("")
+		return diags
+	} else if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get JPS installation informations",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	_ = d.Set("app_unique_name", d.Id())
+	if app, ok := result["app"].(map[string]interface{}); ok {
+		if env_name, ok := app["envName"].(string); ok && env_name != "" {
+			_ = d.Set("env_name", env_name)
+		}
+	}
+
+	return diags
+}
+
+func resourceJelasticJpsInstallDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	_, err := m.doJelasticRequest(API_MARKETPLACE_JPS_UNINSTALL_ENDPOINT, url.Values{
+		"appUniqueName": {d.Id()},
+		"force":         {"true"},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Unable to uninstall JPS application %s", d.Id()),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	return diags
+}
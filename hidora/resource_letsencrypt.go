@@ -0,0 +1,249 @@
+package hidora
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	API_MARKETPLACE_JPS_INSTALL_ENDPOINT          string = "marketplace/jps/rest/install"
+	API_MARKETPLACE_JPS_UNINSTALL_ENDPOINT        string = "marketplace/jps/rest/uninstall"
+	API_MARKETPLACE_JPS_EXECUTEAPPACTION_ENDPOINT string = "marketplace/jps/rest/executeappaction"
+	API_MARKETPLACE_APP_GETADDONLIST_ENDPOINT     string = "marketplace/app/rest/getaddonlist"
+	LETSENCRYPT_APP_ID                            string = "letsencrypt-ssl-addon"
+	LETSENCRYPT_CONFIGURE_ACTION                  string = "configure"
+	LETSENCRYPT_SETTINGS_CUSTOMDOMAINS            string = "customDomains"
+	LETSENCRYPT_SETTINGS_CERTIFICATE_STATUS       string = "status"
+	LETSENCRYPT_SETTINGS_CERTIFICATE_RENEWAL_DATE string = "renewalDate"
+)
+
+func resourceHidoraLetsEncrypt() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceJelasticLetsEncryptCreate,
+		ReadContext:   resourceJelasticLetsEncryptRead,
+		UpdateContext: resourceJelasticLetsEncryptUpdate,
+		DeleteContext: resourceJelasticLetsEncryptDelete,
+		CustomizeDiff: checkResourceEndpoints(
+			API_MARKETPLACE_JPS_INSTALL_ENDPOINT,
+			API_MARKETPLACE_APP_GETADDONLIST_ENDPOINT,
+			API_MARKETPLACE_JPS_EXECUTEAPPACTION_ENDPOINT,
+			API_MARKETPLACE_JPS_UNINSTALL_ENDPOINT,
+		),
+		SchemaVersion: 0,
+		Schema: map[string]*schema.Schema{
+			"env_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the environment where the add-on is installed",
+			},
+			"nodegroup": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Node group which receives the certificate",
+			},
+			"custom_domains": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Custom domains bound to the environment, the environment domain is used when empty",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"certificate_status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Status of the certificate reported by the add-on",
+			},
+			"renewal_date": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Date of the next certificate renewal reported by the add-on",
+			},
+		},
+	}
+}
+
+func resourceJelasticLetsEncryptCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	settings_json, _ := json.Marshal(expandLetsEncryptSettings(d))
+
+	result, err := m.doJelasticRequest(API_MARKETPLACE_JPS_INSTALL_ENDPOINT, url.Values{
+		"jps":       {LETSENCRYPT_APP_ID},
+		"envName":   {d.Get("env_name").(string)},
+		"nodeGroup": {d.Get("nodegroup").(string)},
+		"settings":  {string(settings_json)},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to install Let's Encrypt add-on",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	unique_name, ok := result["uniqueName"].(string)
+	if !ok {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to install Let's Encrypt add-on",
+			Detail:   "API response doesn't contain the unique name of the add-on",
+		})
+		return diags
+	}
+	d.SetId(unique_name)
+
+	return resourceJelasticLetsEncryptRead(ctx, d, meta)
+}
+
+func resourceJelasticLetsEncryptRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	result, err := m.doJelasticRequest(API_MARKETPLACE_APP_GETADDONLIST_ENDPOINT, url.Values{
+		"envName":   {d.Get("env_name").(string)},
+		"nodeGroup": {d.Get("nodegroup").(string)},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get add-ons informations",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	addon := findInstalledAddon(result, d.Id())
+	if addon == nil {
+		// Add-on has been uninstalled outside of Terraform
+		d.SetId("")
+		return diags
+	}
+	settings := flattenAddonSettings(addon)
+
+	if custom_domains, ok := settings[LETSENCRYPT_SETTINGS_CUSTOMDOMAINS].(string); ok {
+		_ = d.Set("custom_domains", splitDomains(custom_domains))
+	}
+	certificate_status, _ := settings[LETSENCRYPT_SETTINGS_CERTIFICATE_STATUS].(string)
+	renewal_date, _ := settings[LETSENCRYPT_SETTINGS_CERTIFICATE_RENEWAL_DATE].(string)
+	_ = d.Set("certificate_status", certificate_status)
+	_ = d.Set("renewal_date", renewal_date)
+
+	return diags
+}
+
+// Only custom_domains can be updated, through the configure action of the add-on
+func resourceJelasticLetsEncryptUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	if d.HasChange("custom_domains") {
+		settings_json, _ := json.Marshal(expandLetsEncryptSettings(d))
+
+		_, err := m.doJelasticRequest(API_MARKETPLACE_JPS_EXECUTEAPPACTION_ENDPOINT, url.Values{
+			"appUniqueName": {d.Id()},
+			"action":        {LETSENCRYPT_CONFIGURE_ACTION},
+			"settings":      {string(settings_json)},
+		})
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to update Let's Encrypt add-on",
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+	}
+
+	return resourceJelasticLetsEncryptRead(ctx, d, meta)
+}
+
+func resourceJelasticLetsEncryptDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	_, err := m.doJelasticRequest(API_MARKETPLACE_JPS_UNINSTALL_ENDPOINT, url.Values{
+		"appUniqueName": {d.Id()},
+		"force":         {"true"},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Unable to uninstall Let's Encrypt add-on %s", d.Id()),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	return diags
+}
+
+func expandLetsEncryptSettings(d *schema.ResourceData) map[string]interface{} {
+	tf_domains := d.Get("custom_domains").([]interface{})
+	domains := make([]string, len(tf_domains))
+	for i, v := range tf_domains {
+		domains[i], _ = v.(string)
+	}
+	return map[string]interface{}{
+		LETSENCRYPT_SETTINGS_CUSTOMDOMAINS: strings.Join(domains, " "),
+		"nodeGroup":                        d.Get("nodegroup").(string),
+	}
+}
+
+// Search an installed add-on by its unique name in a getaddonlist response
+func findInstalledAddon(result map[string]interface{}, unique_name string) map[string]interface{} {
+	apps, _ := result["apps"].([]interface{})
+	for _, app := range apps {
+		app_map, ok := app.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if app_map["uniqueName"] == unique_name {
+			if is_installed, ok := app_map["isInstalled"].(bool); ok && !is_installed {
+				return nil
+			}
+			return app_map
+		}
+	}
+	return nil
+}
+
+// Settings of an add-on are sometimes nested in a data object
+func flattenAddonSettings(addon map[string]interface{}) map[string]interface{} {
+	settings, ok := addon["settings"].(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}
+	}
+	if data, ok := settings["data"].(map[string]interface{}); ok {
+		return data
+	}
+	return settings
+}
+
+// Domains are separated by spaces, commas or semicolons in the add-on settings
+func splitDomains(domains string) []string {
+	return strings.FieldsFunc(domains, func(r rune) bool {
+		return r == ' ' || r == ',' || r == ';'
+	})
+}
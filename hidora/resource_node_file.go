@@ -0,0 +1,210 @@
+package hidora
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	API_ENV_FILE_DELETE_ENDPOINT          string = "environment/file/rest/delete"
+	API_ENV_CONTROL_RESTARTNODES_ENDPOINT string = "environment/control/rest/restartnodes"
+)
+
+func resourceHidoraNodeFile() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceJelasticNodeFileCreate,
+		ReadContext:   resourceJelasticNodeFileRead,
+		UpdateContext: resourceJelasticNodeFileUpdate,
+		DeleteContext: resourceJelasticNodeFileDelete,
+		CustomizeDiff: resourceJelasticNodeFileCustomizeDiff,
+		SchemaVersion: 0,
+		Schema: map[string]*schema.Schema{
+			"env_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the environment",
+			},
+			"nodegroup": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Node group where the file is written",
+			},
+			"path": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Absolute path of the file in the containers",
+			},
+			"content": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Content of the file",
+			},
+			"restart": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Restart the node group after the file is changed, honouring its restartdelay",
+			},
+			"delete_on_destroy": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Delete the file from the node group on destroy, it's left in place otherwise",
+			},
+			"content_hash": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "SHA256 of the content read on the node group",
+			},
+		},
+	}
+}
+
+func resourceJelasticNodeFileCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	if err := writeNodeFile(meta.(*Client), d); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Unable to write %s", d.Get("path").(string)),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	d.SetId(fmt.Sprintf("%s/%s:%s",
+		d.Get("env_name").(string),
+		d.Get("nodegroup").(string),
+		d.Get("path").(string)))
+
+	return resourceJelasticNodeFileRead(ctx, d, meta)
+}
+
+func resourceJelasticNodeFileRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	result, err := m.doJelasticRequest(API_ENV_FILE_READ_ENDPOINT, url.Values{
+		"envName":   {d.Get("env_name").(string)},
+		"nodeGroup": {d.Get("nodegroup").(string)},
+		"path":      {d.Get("path").(string)},
+	})
+	if isJelasticResult(err, API_RESULT_FILE_NOT_FOUND) {
+		// File has been removed outside of Terraform
+		d.SetId("")
+		return diags
+	} else if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Unable to read %s", d.Get("path").(string)),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	body, _ := result["body"].(string)
+	content_hash := nodeFileHash(body)
+	// Content is only replaced when the file has been changed outside of Terraform
+	if content_hash != nodeFileHash(d.Get("content").(string)) {
+		_ = d.Set("content", body)
+	}
+	_ = d.Set("content_hash", content_hash)
+
+	return diags
+}
+
+func resourceJelasticNodeFileUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	if d.HasChange("content") {
+		if err := writeNodeFile(meta.(*Client), d); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Unable to write %s", d.Get("path").(string)),
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+	}
+
+	return resourceJelasticNodeFileRead(ctx, d, meta)
+}
+
+func resourceJelasticNodeFileDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	// The file may have existed before Terraform managed it
+	if !d.Get("delete_on_destroy").(bool) {
+		return diags
+	}
+
+	_, err := m.doJelasticRequest(API_ENV_FILE_DELETE_ENDPOINT, url.Values{
+		"envName":   {d.Get("env_name").(string)},
+		"nodeGroup": {d.Get("nodegroup").(string)},
+		"path":      {d.Get("path").(string)},
+	})
+	if err != nil && !isJelasticResult(err, API_RESULT_FILE_NOT_FOUND) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Unable to delete %s", d.Get("path").(string)),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	return diags
+}
+
+// Fail the plan when the access token can't call the endpoints of the file
+func resourceJelasticNodeFileCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	endpoints := []string{API_ENV_FILE_READ_ENDPOINT, API_ENV_FILE_WRITE_ENDPOINT}
+	if d.Get("restart").(bool) {
+		// restartNodeGroup lists the nodes with getenvinfo before restarting them
+		endpoints = append(endpoints, API_ENV_CONTROL_GETENVINFO_ENDPOINT, API_ENV_CONTROL_RESTARTNODES_ENDPOINT)
+	}
+	if d.Get("delete_on_destroy").(bool) {
+		endpoints = append(endpoints, API_ENV_FILE_DELETE_ENDPOINT)
+	}
+	return meta.(*Client).checkEndpointsAllowed(endpoints...)
+}
+
+// Write the file on every node of the node group then restart them if asked
+func writeNodeFile(m *Client, d *schema.ResourceData) error {
+	_, err := m.doJelasticRequest(API_ENV_FILE_WRITE_ENDPOINT, url.Values{
+		"envName":      {d.Get("env_name").(string)},
+		"nodeGroup":    {d.Get("nodegroup").(string)},
+		"path":         {d.Get("path").(string)},
+		"body":         {d.Get("content").(string)},
+		"isAppendMode": {"false"},
+	})
+	if err != nil {
+		return err
+	}
+
+	if d.Get("restart").(bool) {
+		_, err = restartNodeGroup(m, d.Get("env_name").(string), d.Get("nodegroup").(string), -1)
+	}
+	return err
+}
+
+func nodeFileHash(content string) string {
+	hash := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,125 @@
+package hidora
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceHidoraNodeRestart() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceJelasticNodeRestartCreate,
+		ReadContext:   resourceJelasticNodeRestartRead,
+		DeleteContext: resourceJelasticNodeRestartDelete,
+		CustomizeDiff: checkResourceEndpoints(
+			API_ENV_CONTROL_GETENVINFO_ENDPOINT,
+			API_ENV_CONTROL_RESTARTNODES_ENDPOINT,
+		),
+		SchemaVersion: 0,
+		Schema: map[string]*schema.Schema{
+			"env_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the environment",
+			},
+			"nodegroup": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Node group to restart",
+			},
+			"delay": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Delay in seconds between the restart of two nodes, restartdelay of the node group when empty",
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Arbitrary values which restart the node group when changed",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func resourceJelasticNodeRestartCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	delay := -1
+	if v, ok := d.GetOkExists("delay"); ok {
+		delay = v.(int)
+	}
+
+	delay, err := restartNodeGroup(m, d.Get("env_name").(string), d.Get("nodegroup").(string), delay)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Unable to restart %s", d.Get("nodegroup").(string)),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	_ = d.Set("delay", delay)
+	d.SetId(strconv.FormatInt(time.Now().UnixNano(), 10))
+
+	return diags
+}
+
+// Nothing to read, a restart is an action
+func resourceJelasticNodeRestartRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+func resourceJelasticNodeRestartDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId("")
+	return nil
+}
+
+// Restart the nodes of a node group one after the other. restartNodeDelay of
+// the node group is used when delay is negative. The delay used is returned.
+func restartNodeGroup(m *Client, env_name string, nodegroup string, delay int) (int, error) {
+	if delay < 0 {
+		result, err := m.doJelasticRequest(API_ENV_CONTROL_GETENVINFO_ENDPOINT, url.Values{
+			"envName": {env_name},
+			"lazy":    {"false"},
+		})
+		if err != nil {
+			return delay, err
+		}
+		delay = 0
+		nodegroups, _ := result["nodeGroups"].([]interface{})
+		for _, nodegroup_infos := range nodegroups {
+			nodegroup_infos_map := nodegroup_infos.(map[string]interface{})
+			if nodegroup_infos_map["name"] == nodegroup {
+				restart_delay, _ := nodegroup_infos_map["restartNodeDelay"].(float64)
+				delay = int(restart_delay)
+			}
+		}
+	}
+
+	_, err := m.doJelasticRequest(API_ENV_CONTROL_RESTARTNODES_ENDPOINT, url.Values{
+		"envName":      {env_name},
+		"nodeGroup":    {nodegroup},
+		"delay":        {strconv.Itoa(delay)},
+		"isSequential": {"true"},
+	})
+	return delay, err
+}
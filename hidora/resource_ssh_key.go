@@ -0,0 +1,223 @@
+package hidora
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	API_USERS_ACCOUNT_ADDSSHKEY_ENDPOINT    string = "users/account/rest/addsshkey"
+	API_USERS_ACCOUNT_DELETESSHKEY_ENDPOINT string = "users/account/rest/deletesshkey"
+	API_USERS_ACCOUNT_GETSSHKEYS_ENDPOINT   string = "users/account/rest/getsshkeys"
+)
+
+func resourceHidoraSshKey() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceJelasticSshKeyCreate,
+		ReadContext:   resourceJelasticSshKeyRead,
+		DeleteContext: resourceJelasticSshKeyDelete,
+		CustomizeDiff: checkResourceEndpoints(
+			API_USERS_ACCOUNT_ADDSSHKEY_ENDPOINT,
+			API_USERS_ACCOUNT_GETSSHKEYS_ENDPOINT,
+			API_USERS_ACCOUNT_DELETESSHKEY_ENDPOINT,
+		),
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		SchemaVersion: 0,
+		Schema: map[string]*schema.Schema{
+			"title": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Title of the key in the account",
+			},
+			"public_key": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateSshPublicKey,
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return strings.TrimSpace(old) == strings.TrimSpace(new)
+				},
+				Description: "Public key in the authorized_keys format",
+			},
+			"fingerprint": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "SHA256 fingerprint of the public key",
+			},
+		},
+	}
+}
+
+func resourceJelasticSshKeyCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	public_key := strings.TrimSpace(d.Get("public_key").(string))
+	fingerprint, err := sshKeyFingerprint(public_key)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid SSH public key",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	_, err = m.doJelasticRequest(API_USERS_ACCOUNT_ADDSSHKEY_ENDPOINT, url.Values{
+		"title":     {d.Get("title").(string)},
+		"sshKey":    {public_key},
+		"isPrivate": {"false"},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to add SSH key",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	// addsshkey doesn't return the key, search it by fingerprint
+	keys, err := getSshKeys(m)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get SSH keys",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	for _, key := range keys {
+		if key["fingerprint"] == fingerprint && key["title"] == d.Get("title").(string) {
+			d.SetId(strconv.Itoa(key["id"].(int)))
+			break
+		}
+	}
+	if d.Id() == "" {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to add SSH key",
+			Detail:   fmt.Sprintf("Key %s not found after being added", fingerprint),
+		})
+		return diags
+	}
+
+	return resourceJelasticSshKeyRead(ctx, d, meta)
+}
+
+func resourceJelasticSshKeyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	keys, err := getSshKeys(m)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get SSH keys",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	for _, key := range keys {
+		if strconv.Itoa(key["id"].(int)) != d.Id() {
+			continue
+		}
+		_ = d.Set("title", key["title"])
+		_ = d.Set("fingerprint", key["fingerprint"])
+		// Keep the key of the configuration unless it has been replaced
+		state_fingerprint, _ := sshKeyFingerprint(d.Get("public_key").(string))
+		if state_fingerprint != key["fingerprint"] {
+			_ = d.Set("public_key", key["public_key"])
+		}
+		return diags
+	}
+
+	// Key has been removed outside of Terraform
+	d.SetId("")
+	return diags
+}
+
+func resourceJelasticSshKeyDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Statement of m and type assertion with *CLient
+	m := meta.(*Client)
+
+	// Declare diag variable for debugging
+	var diags diag.Diagnostics
+
+	_, err := m.doJelasticRequest(API_USERS_ACCOUNT_DELETESSHKEY_ENDPOINT, url.Values{
+		"id": {d.Id()},
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Unable to delete SSH key %s", d.Id()),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	return diags
+}
+
+// Public keys of the account, the fingerprint is computed locally
+func getSshKeys(m *Client) ([]map[string]interface{}, error) {
+	result, err := m.doJelasticRequest(API_USERS_ACCOUNT_GETSSHKEYS_ENDPOINT, url.Values{
+		"isPrivate": {"false"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	flatten_keys := []map[string]interface{}{}
+	keys, _ := result["keys"].([]interface{})
+	for _, key := range keys {
+		key_map, _ := key.(map[string]interface{})
+		// A key without ID can't be managed, nor looked up
+		id, ok := key_map["id"].(float64)
+		if !ok {
+			continue
+		}
+		public_key, _ := key_map["sshKey"].(string)
+		title, _ := key_map["title"].(string)
+		fingerprint, _ := sshKeyFingerprint(public_key)
+		flatten_keys = append(flatten_keys, map[string]interface{}{
+			"id":          int(id),
+			"title":       title,
+			"public_key":  public_key,
+			"fingerprint": fingerprint,
+		})
+	}
+	return flatten_keys, nil
+}
+
+func sshKeyFingerprint(public_key string) (string, error) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(public_key))
+	if err != nil {
+		return "", err
+	}
+	return ssh.FingerprintSHA256(key), nil
+}
+
+func validateSshPublicKey(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := sshKeyFingerprint(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q is not a valid public key in the authorized_keys format: %s", k, err))
+	}
+	return
+}
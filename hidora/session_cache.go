@@ -0,0 +1,143 @@
+package hidora
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	SESSION_CACHE_DEFAULT_DIR string = "~/.hidora/sessions"
+	SESSION_CACHE_KEY_ENV     string = "HIDORA_SESSION_CACHE_KEY"
+	SESSION_CACHE_SALT_LENGTH int    = 16
+)
+
+// Encrypted session stored in the cache directory
+type CachedSession struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// Sessions of a host and a user kept between runs, encrypted with AES-GCM
+// and a key derived from HIDORA_SESSION_CACHE_KEY or the password. A nil
+// cache is disabled.
+type SessionCache struct {
+	path   string
+	secret []byte
+	// Host and user, authenticated with the session
+	identity []byte
+}
+
+func newSessionCache(dir string, host string, username string, password string) *SessionCache {
+	secret := os.Getenv(SESSION_CACHE_KEY_ENV)
+	if secret == "" {
+		secret = password
+	}
+	identity := host + "\x00" + username
+	name := sha256.Sum256([]byte(identity))
+	return &SessionCache{
+		path:     filepath.Join(expandHomeDir(dir), hex.EncodeToString(name[:])),
+		secret:   []byte(secret),
+		identity: []byte(identity),
+	}
+}
+
+// Cipher of the cache for a salt
+func (s *SessionCache) aead(salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(s.secret, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Return the cached session, empty when there is none or when it can't be
+// decrypted (password or key changed)
+func (s *SessionCache) load() string {
+	if s == nil {
+		return ""
+	}
+	content, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return ""
+	}
+	var cached_session CachedSession
+	if err := json.Unmarshal(content, &cached_session); err != nil {
+		log.Printf("[WARN] Invalid session cache %s: %s", s.path, err)
+		return ""
+	}
+	aead, err := s.aead(cached_session.Salt)
+	if err != nil || len(cached_session.Nonce) != aead.NonceSize() {
+		return ""
+	}
+	session, err := aead.Open(nil, cached_session.Nonce, cached_session.Data, s.identity)
+	if err != nil {
+		log.Printf("[WARN] Unable to decrypt session cache %s, key has changed", s.path)
+		return ""
+	}
+	return string(session)
+}
+
+// Save a session, errors are only logged as the cache is an optimisation
+func (s *SessionCache) store(session string) {
+	if s == nil {
+		return
+	}
+	if err := s.write(session); err != nil {
+		log.Printf("[WARN] Unable to write session cache %s: %s", s.path, err)
+	}
+}
+
+func (s *SessionCache) write(session string) error {
+	cached_session := CachedSession{
+		Salt: make([]byte, SESSION_CACHE_SALT_LENGTH),
+	}
+	if _, err := rand.Read(cached_session.Salt); err != nil {
+		return err
+	}
+	aead, err := s.aead(cached_session.Salt)
+	if err != nil {
+		return err
+	}
+	cached_session.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(cached_session.Nonce); err != nil {
+		return err
+	}
+	cached_session.Data = aead.Seal(nil, cached_session.Nonce, []byte(session), s.identity)
+
+	content, _ := json.Marshal(cached_session)
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	// Write then rename so that concurrent runs never read half a file
+	tmp_path := fmt.Sprintf("%s.%d", s.path, os.Getpid())
+	if err := ioutil.WriteFile(tmp_path, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp_path, s.path)
+}
+
+// Whether a session is still accepted by the platform
+func isSessionValid(c *Client, session string) bool {
+	_, err := c.sendJelasticRequest(API_USERS_AUTH_CHECKSIGN_ENDPOINT, url.Values{
+		"appid":   {c.AppId},
+		"session": {session},
+	})
+	return err == nil
+}
@@ -0,0 +1,139 @@
+package hidora
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	API_USERS_AUTH_CHECKSIGN_ENDPOINT string        = "users/authentication/rest/checksign"
+	API_USERS_AUTH_GETTOKENS_ENDPOINT string        = "users/authentication/rest/gettokens"
+	TOKEN_DATETIME_FORMAT             string        = "2006-01-02 15:04:05"
+	TOKEN_EXPIRATION_WARNING          time.Duration = 7 * 24 * time.Hour
+)
+
+// Check that the access token of the client is valid and not expired, and
+// record the API methods it's allowed to call
+func introspectToken(c *Client) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if _, err := c.doJelasticRequest(API_USERS_AUTH_CHECKSIGN_ENDPOINT, url.Values{}); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid access token",
+			Detail:   fmt.Sprintf("The access token is unknown or expired: %s", err),
+		})
+		return diags
+	}
+
+	result, err := c.doJelasticRequest(API_USERS_AUTH_GETTOKENS_ENDPOINT, url.Values{})
+	if err != nil {
+		// Valid token which isn't allowed to list tokens, scopes are unknown
+		log.Printf("[WARN] Unable to get permissions of the access token: %s", err)
+		return diags
+	}
+
+	tokens, _ := result["array"].([]interface{})
+	for _, token := range tokens {
+		token_map := token.(map[string]interface{})
+		if is_current, _ := token_map["isCurrent"].(bool); !is_current {
+			continue
+		}
+
+		if expires_at, ok := token_map["expiresAt"].(string); ok && expires_at != "" {
+			expiration, err := time.Parse(TOKEN_DATETIME_FORMAT, expires_at)
+			if err == nil {
+				c.TokenExpiresAt = expiration
+				if time.Now().After(expiration) {
+					diags = append(diags, diag.Diagnostic{
+						Severity: diag.Error,
+						Summary:  "Expired access token",
+						Detail:   fmt.Sprintf("The access token expired on %s", expires_at),
+					})
+					return diags
+				}
+				if time.Until(expiration) < TOKEN_EXPIRATION_WARNING {
+					diags = append(diags, diag.Diagnostic{
+						Severity: diag.Warning,
+						Summary:  "Access token expires soon",
+						Detail:   fmt.Sprintf("The access token expires on %s", expires_at),
+					})
+				}
+			}
+		}
+
+		// No list of methods means every method is allowed
+		api_list, ok := token_map["apiList"].([]interface{})
+		if !ok || len(api_list) == 0 {
+			return diags
+		}
+		c.AllowedMethods = make(map[string]bool)
+		for _, api := range api_list {
+			switch api := api.(type) {
+			case string:
+				c.AllowedMethods[strings.ToLower(api)] = true
+			case map[string]interface{}:
+				if method, ok := api["method"].(string); ok {
+					c.AllowedMethods[strings.ToLower(method)] = true
+				}
+			}
+		}
+		return diags
+	}
+
+	log.Printf("[WARN] Access token not found in %s, its permissions are unknown", API_USERS_AUTH_GETTOKENS_ENDPOINT)
+	return diags
+}
+
+// API method of an endpoint, environment/control/rest/getenvinfo is
+// environment.control.getenvinfo
+func apiMethodName(endpoint string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.Replace(endpoint, "/rest/", "/", 1), "/", "."))
+}
+
+// Whether the access token is allowed to call an endpoint. Methods can be
+// allowed one by one or by service with a wildcard (environment.control.*).
+func (c *Client) isEndpointAllowed(endpoint string) bool {
+	if c.AllowedMethods == nil {
+		return true
+	}
+	method := apiMethodName(endpoint)
+	if c.AllowedMethods[method] || c.AllowedMethods["*"] {
+		return true
+	}
+	for i := strings.LastIndex(method, "."); i > 0; i = strings.LastIndex(method[:i], ".") {
+		if c.AllowedMethods[method[:i]+".*"] {
+			return true
+		}
+	}
+	return false
+}
+
+// Return an error listing the endpoints the access token isn't allowed to call
+func (c *Client) checkEndpointsAllowed(endpoints ...string) error {
+	forbidden := []string{}
+	for _, endpoint := range endpoints {
+		if !c.isEndpointAllowed(endpoint) {
+			forbidden = append(forbidden, apiMethodName(endpoint))
+		}
+	}
+	if len(forbidden) > 0 {
+		return fmt.Errorf("the access token isn't allowed to call %s", strings.Join(forbidden, ", "))
+	}
+	return nil
+}
+
+// CustomizeDiff of the resources which fail the plan, rather than in the
+// middle of the apply, when the access token can't call their endpoints
+func checkResourceEndpoints(endpoints ...string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		return meta.(*Client).checkEndpointsAllowed(endpoints...)
+	}
+}